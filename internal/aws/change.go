@@ -1,73 +1,119 @@
 package aws
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"strings"
-	"time"
+	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/sso/types"
 	"github.com/fatih/color"
 	"github.com/google/uuid"
+	"github.com/jacbart/jaws/utils/helpers"
 )
 
-func UpdateSecretString(ctx context.Context, client *secretsmanager.Client, secretID string, secretString string) error {
+// secretValue returns the value as a SecretString, or as a SecretBinary when it is not valid UTF-8
+func secretValue(value []byte) (*string, []byte) {
+	if utf8.Valid(value) {
+		return aws.String(string(value)), nil
+	}
+	return nil, value
+}
+
+// UpdateSecretValue pushes a new version of the secret, the replaced versions keep a jaws-vN label
+func UpdateSecretValue(ctx context.Context, client *secretsmanager.Client, secretID string, value []byte) error {
+	if err := pushValue(ctx, client, secretID, value); err != nil {
+		return err
+	}
+	helpers.Infof("%s %s\n", secretID, color.YellowString("updated"))
+	return nil
+}
+
+// RestoreVersion pushes the value of an older version of the secret as a new version, so the versions in
+// between stay readable and the rollback itself can be rolled back
+func RestoreVersion(ctx context.Context, client *secretsmanager.Client, secretID string, versionID string) error {
+	vout, err := GetVersion(ctx, client, secretID, versionID)
+	if err != nil {
+		return err
+	}
+	value := vout.SecretBinary
+	if vout.SecretString != nil {
+		value = []byte(*vout.SecretString)
+	}
+	if err = pushValue(ctx, client, secretID, value); err != nil {
+		return err
+	}
+	helpers.Infof("%s %s\n", secretID, color.YellowString("rolled back to version %s", versionID))
+	return nil
+}
+
+// pushValue pushes the value as a new version of the secret and moves the jaws-vN labels
+func pushValue(ctx context.Context, client *secretsmanager.Client, secretID string, value []byte) error {
+	stages, err := VersionStages(ctx, client, secretID)
+	if err != nil {
+		return err
+	}
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancel()
 	newVersionID := uuid.New()
 
 	updateSecretInput := &secretsmanager.UpdateSecretInput{
 		SecretId:           aws.String(secretID),
 		ClientRequestToken: aws.String(newVersionID.String()),
-		SecretString:       aws.String(secretString),
 	}
-	_, err := client.UpdateSecret(ctx, updateSecretInput)
+	updateSecretInput.SecretString, updateSecretInput.SecretBinary = secretValue(value)
+	_, err = client.UpdateSecret(timeCtx, updateSecretInput)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("%s %s\n", secretID, color.YellowString("updated"))
-
-	return nil
+	return RotateStages(ctx, client, secretID, stages)
 }
 
-func RollbackSecret(ctx context.Context, client *secretsmanager.Client, secretID string) error {
-	timeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-	listVerionInput := &secretsmanager.ListSecretVersionIdsInput{
-		SecretId: aws.String(secretID),
-	}
-	updateVersionOutput, err := client.ListSecretVersionIds(timeCtx, listVerionInput)
+// RollbackSecret makes the version pushed the given number of steps before the current one the current version,
+// the jaws-vN labels are moved like after a push so the replaced current version becomes jaws-v1
+func RollbackSecret(ctx context.Context, client *secretsmanager.Client, secretID string, steps int) error {
+	stages, err := VersionStages(ctx, client, secretID)
 	if err != nil {
 		return err
 	}
-	l := len(updateVersionOutput.Versions)
-	var newPrevious *string
-	var newCurrent *string
-	for i := 0; i < l; i++ {
-		if updateVersionOutput.Versions[i].VersionStages[0] == "AWSCURRENT" {
-			newPrevious = updateVersionOutput.Versions[i].VersionId
-		} else if updateVersionOutput.Versions[i].VersionStages[0] == "AWSPREVIOUS" {
-			newCurrent = updateVersionOutput.Versions[i].VersionId
-		}
+	newCurrent := stages[StageName(steps)]
+	if newCurrent == "" && steps == 1 {
+		newCurrent = stages[previousStage]
 	}
+	if newCurrent == "" {
+		return fmt.Errorf("%s has no version %d step(s) back, only the last %d versions are kept", secretID, steps, MaxStages)
+	}
+
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancel()
 	updateVersionInput := &secretsmanager.UpdateSecretVersionStageInput{
 		SecretId:            aws.String(secretID),
-		VersionStage:        aws.String("AWSCURRENT"),
-		MoveToVersionId:     newCurrent,
-		RemoveFromVersionId: newPrevious,
+		VersionStage:        aws.String(currentStage),
+		MoveToVersionId:     aws.String(newCurrent),
+		RemoveFromVersionId: aws.String(stages[currentStage]),
 	}
 
 	_, err = client.UpdateSecretVersionStage(timeCtx, updateVersionInput)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("%s %s\n", secretID, color.YellowString("rolled back to previous version"))
+	if err = RotateStages(ctx, client, secretID, stages); err != nil {
+		return err
+	}
+	if steps == 1 {
+		helpers.Infof("%s %s\n", secretID, color.YellowString("rolled back to previous version"))
+	} else {
+		helpers.Infof("%s %s\n", secretID, color.YellowString("rolled back %d versions", steps))
+	}
 	return nil
 }
 
-func CheckIfUpdate(ctx context.Context, client *secretsmanager.Client, secretID string, updatedString string) (bool, error) {
-	timeCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+// CheckIfUpdate reports whether the value differs from the current string or binary value of the secret
+func CheckIfUpdate(ctx context.Context, client *secretsmanager.Client, secretID string, updated []byte) (bool, error) {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.GetTimeout)
 	defer cancel()
 
 	var rnfErr *types.ResourceNotFoundException
@@ -83,9 +129,9 @@ func CheckIfUpdate(ctx context.Context, client *secretsmanager.Client, secretID
 			return false, err
 		}
 	}
-	diffCheck := strings.Compare(*secretValueOutput.SecretString, updatedString)
-	if diffCheck == 0 {
-		return false, nil
+	current := secretValueOutput.SecretBinary
+	if secretValueOutput.SecretString != nil {
+		current = []byte(*secretValueOutput.SecretString)
 	}
-	return true, nil
+	return !bytes.Equal(current, updated), nil
 }
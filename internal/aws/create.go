@@ -4,56 +4,69 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
 	"github.com/fatih/color"
 	"github.com/google/uuid"
+	"github.com/jacbart/jaws/utils/helpers"
 )
 
-func CreateSecret(ctx context.Context, client *secretsmanager.Client, secretID string, secretString string) error {
-	timeCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+// CreateOptions are the settings of newly created secrets
+type CreateOptions struct {
+	// Tags are set on the secret when it is created
+	Tags map[string]string
+	// KMSKeyID is the id, arn or alias of the kms key encrypting the secret, aws/secretsmanager when empty
+	KMSKeyID string
+	// ReplicaRegions are the regions the secret is replicated to
+	ReplicaRegions []string
+}
+
+// CreateSecret creates the secret, values that are not valid UTF-8 are stored as a SecretBinary
+func CreateSecret(ctx context.Context, client *secretsmanager.Client, secretID string, value []byte, opts CreateOptions) error {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
 	defer cancel()
 	newRequestToken := uuid.New()
 
 	createSecretInput := &secretsmanager.CreateSecretInput{
 		Name:               aws.String(secretID),
 		ClientRequestToken: aws.String(newRequestToken.String()),
-		SecretString:       aws.String(secretString),
+	}
+	createSecretInput.SecretString, createSecretInput.SecretBinary = secretValue(value)
+	if opts.KMSKeyID != "" {
+		createSecretInput.KmsKeyId = aws.String(opts.KMSKeyID)
+	}
+	createSecretInput.AddReplicaRegions = replicaRegions(opts.ReplicaRegions)
+	for k, v := range opts.Tags {
+		createSecretInput.Tags = append(createSecretInput.Tags, types.Tag{Key: aws.String(k), Value: aws.String(v)})
 	}
 
 	_, err := client.CreateSecret(timeCtx, createSecretInput)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("%s %s\n", secretID, color.MagentaString("created"))
+	helpers.Infof("%s %s\n", secretID, color.MagentaString("created"))
 	return nil
 }
 
-func HandleUpdateCreate(ctx context.Context, client *secretsmanager.Client, secretID string, secretString string, createPrompt bool) error {
-	var userResponse string
+// HandleUpdateCreate updates the secret or creates it when it does not exist yet, similar returns existing
+// secret names to suggest before prompting for the creation
+func HandleUpdateCreate(ctx context.Context, client *secretsmanager.Client, secretID string, value []byte, createPrompt bool, opts CreateOptions, similar func(string) []string) error {
 	var rnfErr *types.ResourceNotFoundException
-	if err := UpdateSecretString(ctx, client, secretID, string(secretString)); err != nil {
+	if err := UpdateSecretValue(ctx, client, secretID, value); err != nil {
 		if errors.As(err, &rnfErr) {
 			if !createPrompt {
-				fmt.Printf("%s was not found, would you like to create this secret? [y/N] ", secretID)
-				fmt.Scanln(&userResponse)
-
-				userResponse = strings.TrimSpace(userResponse)
-				userResponse = strings.ToLower(userResponse)
-
-				if userResponse == "y" || userResponse == "yes" {
-					if err = CreateSecret(ctx, client, secretID, string(secretString)); err != nil {
+				helpers.PrintSuggestions(secretID, similar(secretID))
+				if helpers.PromptYesNo(fmt.Sprintf("%s was not found, would you like to create this secret?", secretID)) {
+					if err = CreateSecret(ctx, client, secretID, value, opts); err != nil {
 						return err
 					}
 				} else {
-					fmt.Printf("creation of %s %s\n", secretID, color.CyanString("skipped"))
+					helpers.Infof("creation of %s %s\n", secretID, color.CyanString("skipped"))
 				}
 			} else {
-				if err = CreateSecret(ctx, client, secretID, string(secretString)); err != nil {
+				if err = CreateSecret(ctx, client, secretID, value, opts); err != nil {
 					return err
 				}
 			}
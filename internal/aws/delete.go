@@ -2,16 +2,16 @@ package aws
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/fatih/color"
+	"github.com/jacbart/jaws/utils/helpers"
 )
 
 func ScheduleDeletion(ctx context.Context, client *secretsmanager.Client, secretID string, recoveryWindow int64) error {
-	timeCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
 	defer cancel()
 	deleteSecretInput := &secretsmanager.DeleteSecretInput{
 		SecretId:                   aws.String(secretID),
@@ -23,12 +23,12 @@ func ScheduleDeletion(ctx context.Context, client *secretsmanager.Client, secret
 	if err != nil {
 		return err
 	}
-	fmt.Printf("%s set to %s on %s\n", secretID, color.RedString("delete"), color.RedString(deleteSecretOutput.DeletionDate.String()))
+	helpers.Infof("%s set to %s on %s\n", secretID, color.RedString("delete"), color.RedString(deleteSecretOutput.DeletionDate.String()))
 	return nil
 }
 
 func CancelDeletion(ctx context.Context, client *secretsmanager.Client, secretID string) error {
-	timeCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
 	defer cancel()
 	restoreSecretInput := &secretsmanager.RestoreSecretInput{
 		SecretId: aws.String(secretID),
@@ -38,7 +38,20 @@ func CancelDeletion(ctx context.Context, client *secretsmanager.Client, secretID
 	if err != nil {
 		return err
 	}
-	fmt.Printf("%s %s\n", secretID, color.GreenString("restored"))
+	helpers.Infof("%s %s\n", secretID, color.GreenString("restored"))
 
 	return nil
 }
+
+// DeletionDate returns when the secret will be deleted, nil when it is not scheduled for deletion
+func DeletionDate(ctx context.Context, client *secretsmanager.Client, secretID string) (*time.Time, error) {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.GetTimeout)
+	defer cancel()
+	describeSecretOutput, err := client.DescribeSecret(timeCtx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return describeSecretOutput.DeletedDate, nil
+}
@@ -2,46 +2,71 @@ package aws
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
+	"sort"
 
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/jacbart/jaws/utils/helpers"
 )
 
-func GetSecretsList(ctx context.Context, client *secretsmanager.Client, nextToken *string) (*secretsmanager.ListSecretsOutput, error) {
+// GetSecretsList lists a page of secrets, the filters are applied by aws
+func GetSecretsList(ctx context.Context, client *secretsmanager.Client, nextToken *string, filters []types.Filter) (*secretsmanager.ListSecretsOutput, error) {
 	input := &secretsmanager.ListSecretsInput{
 		NextToken: nextToken,
+		Filters:   filters,
 	}
-	result, err := client.ListSecrets(ctx, input)
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.ListTimeout)
+	defer cancel()
+	result, err := client.ListSecrets(timeCtx, input)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
-func GetSecretNames(secretsPath string) ([]string, error) {
-	var secretNames []string
-	err := filepath.WalkDir(secretsPath,
-		func(path string, d os.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-			info, err := os.Lstat(path)
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() {
-				secretID := strings.TrimPrefix(path, fmt.Sprintf("%s/", secretsPath))
-				if !strings.HasPrefix(secretID, ".") {
-					secretNames = append(secretNames, secretID)
-				}
-			}
-			return nil
-		})
-	if err != nil {
-		return []string{}, err
+// maxFilterValues is the most values aws accepts in a single filter
+const maxFilterValues = 10
+
+// NameFilters returns the filter sets matching secrets whose name starts with any of the prefixes, aws limits
+// the values of a filter so one set is returned per 10 prefixes. No prefixes returns a single empty set.
+func NameFilters(prefixes []string) [][]types.Filter {
+	if len(prefixes) == 0 {
+		return [][]types.Filter{nil}
+	}
+	var sets [][]types.Filter
+	for start := 0; start < len(prefixes); start += maxFilterValues {
+		end := start + maxFilterValues
+		if end > len(prefixes) {
+			end = len(prefixes)
+		}
+		sets = append(sets, []types.Filter{{
+			Key:    types.FilterNameStringTypeName,
+			Values: prefixes[start:end],
+		}})
+	}
+	return sets
+}
+
+// TagFilters returns the filters narrowing a listing to secrets with any of the tag keys and values, aws does not
+// match a key to its value so the tags still need to be checked on the listed secrets
+func TagFilters(tags map[string]string) []types.Filter {
+	if len(tags) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]string, 0, len(tags))
+	for _, k := range keys {
+		values = append(values, tags[k])
+	}
+	if len(keys) > maxFilterValues {
+		keys, values = keys[:maxFilterValues], values[:maxFilterValues]
+	}
+	return []types.Filter{
+		{Key: types.FilterNameStringTypeTagKey, Values: keys},
+		{Key: types.FilterNameStringTypeTagValue, Values: values},
 	}
-	return secretNames, nil
 }
@@ -0,0 +1,61 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// ChangedByTag is the tag holding the identity that last pushed a secret
+const ChangedByTag = "last-changed-by"
+
+// CallerIdentity returns the arn of the credentials in the config
+func CallerIdentity(ctx context.Context, cfg aws.Config) (string, error) {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.GetTimeout)
+	defer cancel()
+	out, err := sts.NewFromConfig(cfg).GetCallerIdentity(timeCtx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+	return *out.Arn, nil
+}
+
+// SetChangedBy tags the secret with the identity that pushed it
+func SetChangedBy(ctx context.Context, client *secretsmanager.Client, secretID string, identity string) error {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancel()
+	_, err := client.TagResource(timeCtx, &secretsmanager.TagResourceInput{
+		SecretId: aws.String(secretID),
+		Tags: []types.Tag{{
+			Key:   aws.String(ChangedByTag),
+			Value: aws.String(identity),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	helpers.Verbosef(helpers.VerbosityVerbose, "%s %s=%s\n", secretID, ChangedByTag, identity)
+	return nil
+}
+
+// ChangedBy returns the identity that last pushed the secret, empty when the secret was never tagged by jaws
+func ChangedBy(ctx context.Context, client *secretsmanager.Client, secretID string) (string, error) {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.GetTimeout)
+	defer cancel()
+	out, err := client.DescribeSecret(timeCtx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range out.Tags {
+		if tag.Key != nil && *tag.Key == ChangedByTag && tag.Value != nil {
+			return *tag.Value, nil
+		}
+	}
+	return "", nil
+}
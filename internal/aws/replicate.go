@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// replicaRegions returns the regions as replicas, each encrypted with the aws/secretsmanager key of its region
+func replicaRegions(regions []string) []types.ReplicaRegionType {
+	var replicas []types.ReplicaRegionType
+	for _, region := range regions {
+		replicas = append(replicas, types.ReplicaRegionType{Region: aws.String(region)})
+	}
+	return replicas
+}
+
+// ReplicateSecret replicates the secret to the regions it is not replicated to yet, it returns the regions
+// replication was started for
+func ReplicateSecret(ctx context.Context, client *secretsmanager.Client, secretID string, regions []string, dryRun bool) ([]string, error) {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.GetTimeout)
+	out, err := client.DescribeSecret(timeCtx, &secretsmanager.DescribeSecretInput{
+		SecretId: aws.String(secretID),
+	})
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	replicated := map[string]bool{}
+	for _, status := range out.ReplicationStatus {
+		if status.Region != nil {
+			replicated[*status.Region] = true
+		}
+	}
+	var missing []string
+	for _, region := range regions {
+		if !replicated[region] && !helpers.Contains(missing, region) {
+			missing = append(missing, region)
+		}
+	}
+	if len(missing) == 0 {
+		helpers.Infof("%s %s\n", secretID, color.CyanString("already replicated to %s", strings.Join(regions, ",")))
+		return nil, nil
+	}
+	if dryRun {
+		helpers.Infof("%s %s\n", secretID, color.YellowString("would be replicated to %s", strings.Join(missing, ",")))
+		return missing, nil
+	}
+
+	timeCtx, cancel = context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancel()
+	_, err = client.ReplicateSecretToRegions(timeCtx, &secretsmanager.ReplicateSecretToRegionsInput{
+		SecretId:          aws.String(secretID),
+		AddReplicaRegions: replicaRegions(missing),
+	})
+	if err != nil {
+		return nil, err
+	}
+	helpers.Infof("%s %s\n", secretID, color.GreenString("replicated to %s", strings.Join(missing, ",")))
+	return missing, nil
+}
@@ -0,0 +1,155 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+const (
+	currentStage  = "AWSCURRENT"
+	previousStage = "AWSPREVIOUS"
+	// stagePrefix labels the versions kept by jaws, jaws-v1 is the version before the current one
+	stagePrefix = "jaws-v"
+)
+
+// MaxStages is the number of older versions kept with a jaws-vN staging label so a rollback
+// can go back further than AWSPREVIOUS
+var MaxStages = 5
+
+// StageName returns the staging label of the version n pushes before the current one
+func StageName(n int) string {
+	return fmt.Sprintf("%s%d", stagePrefix, n)
+}
+
+// ListVersions returns every version of the secret including the deprecated versions without a staging label
+func ListVersions(ctx context.Context, client *secretsmanager.Client, secretID string) ([]types.SecretVersionsListEntry, error) {
+	var versions []types.SecretVersionsListEntry
+	var nextToken *string
+	for {
+		timeCtx, cancel := context.WithTimeout(ctx, helpers.GetTimeout)
+		out, err := client.ListSecretVersionIds(timeCtx, &secretsmanager.ListSecretVersionIdsInput{
+			SecretId:          aws.String(secretID),
+			IncludeDeprecated: true,
+			NextToken:         nextToken,
+		})
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, out.Versions...)
+		if out.NextToken == nil {
+			return versions, nil
+		}
+		nextToken = out.NextToken
+	}
+}
+
+// GetVersion returns the value of a version of the secret
+func GetVersion(ctx context.Context, client *secretsmanager.Client, secretID string, versionID string) (*secretsmanager.GetSecretValueOutput, error) {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.GetTimeout)
+	defer cancel()
+	return client.GetSecretValue(timeCtx, &secretsmanager.GetSecretValueInput{
+		SecretId:  aws.String(secretID),
+		VersionId: aws.String(versionID),
+	})
+}
+
+// VersionStages returns the version ID holding each staging label of the secret
+func VersionStages(ctx context.Context, client *secretsmanager.Client, secretID string) (map[string]string, error) {
+	versions, err := ListVersions(ctx, client, secretID)
+	if err != nil {
+		return nil, err
+	}
+	stages := map[string]string{}
+	for _, v := range versions {
+		for _, stage := range v.VersionStages {
+			stages[stage] = *v.VersionId
+		}
+	}
+	return stages, nil
+}
+
+// RotateStages shifts the jaws-vN labels by one version after a push, before holds the labels as they were
+// before the push so its AWSCURRENT version becomes jaws-v1. Labels past MaxStages are dropped.
+func RotateStages(ctx context.Context, client *secretsmanager.Client, secretID string, before map[string]string) error {
+	maxLabel := MaxStages
+	for n := MaxStages + 1; before[StageName(n)] != ""; n++ {
+		maxLabel = n
+	}
+	for n := maxLabel; n >= 1; n-- {
+		stage := StageName(n)
+		from := before[stage]
+		to := ""
+		if n <= MaxStages {
+			if n == 1 {
+				to = before[currentStage]
+			} else {
+				to = before[StageName(n-1)]
+			}
+		}
+		if from == to {
+			continue
+		}
+		input := &secretsmanager.UpdateSecretVersionStageInput{
+			SecretId:     aws.String(secretID),
+			VersionStage: aws.String(stage),
+		}
+		if to != "" {
+			input.MoveToVersionId = aws.String(to)
+		}
+		if from != "" {
+			input.RemoveFromVersionId = aws.String(from)
+		}
+		timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
+		_, err := client.UpdateSecretVersionStage(timeCtx, input)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("moving stage %s of %s: %w", stage, secretID, err)
+		}
+	}
+	helpers.Verbosef(helpers.VerbosityDebug, "rotated version stages of %s\n", secretID)
+	return nil
+}
+
+// DestroyStages removes every staging label from the versions created before the cutoff, deprecating them. They
+// stay readable by version ID until AWS cleans deprecated versions up on its own, the current and previous
+// versions are never changed. It returns the number of versions deprecated.
+func DestroyStages(ctx context.Context, client *secretsmanager.Client, secretID string, before time.Time, dryRun bool) (int, error) {
+	versions, err := ListVersions(ctx, client, secretID)
+	if err != nil {
+		return 0, err
+	}
+	deprecated := 0
+	for _, v := range versions {
+		if v.CreatedDate == nil || !v.CreatedDate.Before(before) || len(v.VersionStages) == 0 ||
+			helpers.Contains(v.VersionStages, currentStage) || helpers.Contains(v.VersionStages, previousStage) {
+			continue
+		}
+		deprecated++
+		if dryRun {
+			helpers.Infof("%s version %s (%s) %s\n", secretID, *v.VersionId, v.CreatedDate.Format("2006-01-02"), color.YellowString("would be deprecated"))
+			continue
+		}
+		for _, stage := range v.VersionStages {
+			timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
+			_, err := client.UpdateSecretVersionStage(timeCtx, &secretsmanager.UpdateSecretVersionStageInput{
+				SecretId:            aws.String(secretID),
+				VersionStage:        aws.String(stage),
+				RemoveFromVersionId: v.VersionId,
+			})
+			cancel()
+			if err != nil {
+				return deprecated - 1, fmt.Errorf("removing stage %s of %s: %w", stage, secretID, err)
+			}
+		}
+		helpers.Infof("%s version %s (%s) %s\n", secretID, *v.VersionId, v.CreatedDate.Format("2006-01-02"), color.RedString("deprecated"))
+	}
+	return deprecated, nil
+}
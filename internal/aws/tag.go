@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+func TagSecret(ctx context.Context, client *secretsmanager.Client, secretID string, tags map[string]string) error {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancel()
+	var keys []string
+	var awsTags []types.Tag
+	for k, v := range tags {
+		keys = append(keys, fmt.Sprintf("%s=%s", k, v))
+		awsTags = append(awsTags, types.Tag{
+			Key:   aws.String(k),
+			Value: aws.String(v),
+		})
+	}
+	sort.Strings(keys)
+
+	tagResourceInput := &secretsmanager.TagResourceInput{
+		SecretId: aws.String(secretID),
+		Tags:     awsTags,
+	}
+	_, err := client.TagResource(timeCtx, tagResourceInput)
+	if err != nil {
+		return err
+	}
+	helpers.Infof("%s %s %s\n", secretID, color.GreenString("tagged"), strings.Join(keys, ","))
+	return nil
+}
+
+func UntagSecret(ctx context.Context, client *secretsmanager.Client, secretID string, keys []string) error {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancel()
+	untagResourceInput := &secretsmanager.UntagResourceInput{
+		SecretId: aws.String(secretID),
+		TagKeys:  keys,
+	}
+	_, err := client.UntagResource(timeCtx, untagResourceInput)
+	if err != nil {
+		return err
+	}
+	helpers.Infof("%s %s %s\n", secretID, color.RedString("untagged"), strings.Join(keys, ","))
+	return nil
+}
+
+// SetDescription replaces the description of the secret, the value is left as is
+func SetDescription(ctx context.Context, client *secretsmanager.Client, secretID string, description string) error {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancel()
+	_, err := client.UpdateSecret(timeCtx, &secretsmanager.UpdateSecretInput{
+		SecretId:    aws.String(secretID),
+		Description: aws.String(description),
+	})
+	if err != nil {
+		return err
+	}
+	helpers.Infof("%s %s\n", secretID, color.GreenString("described"))
+	return nil
+}
@@ -0,0 +1,165 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apiVersion = "7.4"
+	// resource is the audience of the tokens used for Key Vault
+	resource = "https://vault.azure.net"
+	// imdsTokenURL is the instance metadata endpoint handing out managed identity tokens
+	imdsTokenURL = "http://169.254.169.254/metadata/identity/oauth2/token"
+)
+
+// Credentials gets access tokens for Key Vault using a service principal secret or, without one, the managed identity
+type Credentials struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Token returns a cached access token, a new one is requested shortly before it expires
+func (c *Credentials) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Until(c.expires) > time.Minute {
+		return c.token, nil
+	}
+	var req *http.Request
+	var err error
+	if c.ClientSecret != "" {
+		form := url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {c.ClientID},
+			"client_secret": {c.ClientSecret},
+			"scope":         {resource + "/.default"},
+		}
+		tokenURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", url.PathEscape(c.TenantID))
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		query := url.Values{"api-version": {"2018-02-01"}, "resource": {resource}}
+		if c.ClientID != "" {
+			query.Set("client_id", c.ClientID)
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, imdsTokenURL+"?"+query.Encode(), nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata", "true")
+	}
+	var out struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	if err = send(req, &out); err != nil {
+		return "", fmt.Errorf("getting an azure access token: %w", err)
+	}
+	seconds, _ := out.ExpiresIn.Int64()
+	c.token = out.AccessToken
+	c.expires = time.Now().Add(time.Duration(seconds) * time.Second)
+	return c.token, nil
+}
+
+// Error is an error response from Azure
+type Error struct {
+	StatusCode int `json:"-"`
+	Detail     struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (e *Error) Error() string {
+	if e.Detail.Code == "" {
+		return fmt.Sprintf("azure responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("azure responded with status %d: %s: %s", e.StatusCode, e.Detail.Code, e.Detail.Message)
+}
+
+// NotFound reports whether the error is a 404 response from Azure
+func NotFound(err error) bool {
+	azureErr, ok := err.(*Error)
+	return ok && azureErr.StatusCode == http.StatusNotFound
+}
+
+// send does the request and decodes the json response into out when it is not nil
+func send(req *http.Request, out interface{}) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	src, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		azureErr := &Error{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(src, azureErr)
+		return azureErr
+	}
+	if out == nil || len(src) == 0 {
+		return nil
+	}
+	return json.Unmarshal(src, out)
+}
+
+// Client calls the Key Vault secrets API of one vault
+type Client struct {
+	// VaultURL is the address of the vault, i.e. https://my-vault.vault.azure.net
+	VaultURL    string
+	Credentials *Credentials
+}
+
+// do sends the request to the vault, path is either relative to the vault or a full nextLink url
+func (c *Client) do(ctx context.Context, method string, path string, body interface{}, out interface{}) error {
+	u := path
+	if !strings.HasPrefix(path, "https://") {
+		u = strings.TrimSuffix(c.VaultURL, "/") + "/" + strings.TrimPrefix(path, "/")
+		if strings.Contains(u, "?") {
+			u += "&api-version=" + apiVersion
+		} else {
+			u += "?api-version=" + apiVersion
+		}
+	}
+	var reader io.Reader
+	if body != nil {
+		src, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(src)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	token, err := c.Credentials.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return send(req, out)
+}
@@ -0,0 +1,146 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Attributes are the attributes of a secret version
+type Attributes struct {
+	Enabled bool  `json:"enabled"`
+	Created int64 `json:"created"`
+}
+
+// SecretVersion is a version of a secret as listed by Key Vault
+type SecretVersion struct {
+	ID         string     `json:"id"`
+	Attributes Attributes `json:"attributes"`
+}
+
+// Version returns the version part of the id of the secret version
+func (v SecretVersion) Version() string {
+	return path.Base(v.ID)
+}
+
+// DeletedSecret is a secret pending purge that can still be recovered
+type DeletedSecret struct {
+	ID                 string `json:"id"`
+	ScheduledPurgeDate int64  `json:"scheduledPurgeDate"`
+}
+
+// Name returns the name of the deleted secret
+func (d DeletedSecret) Name() string {
+	return path.Base(d.ID)
+}
+
+// PurgeDate returns when the secret is purged for good
+func (d DeletedSecret) PurgeDate() time.Time {
+	return time.Unix(d.ScheduledPurgeDate, 0)
+}
+
+func secretPath(name string, rest ...string) string {
+	return strings.Join(append([]string{"secrets", url.PathEscape(name)}, rest...), "/")
+}
+
+// ListSecrets returns the names of every secret of the vault
+func (c *Client) ListSecrets(ctx context.Context) ([]string, error) {
+	var names []string
+	next := "secrets"
+	for next != "" {
+		var out struct {
+			Value    []SecretVersion `json:"value"`
+			NextLink string          `json:"nextLink"`
+		}
+		if err := c.do(ctx, http.MethodGet, next, nil, &out); err != nil {
+			return nil, err
+		}
+		for _, s := range out.Value {
+			names = append(names, path.Base(s.ID))
+		}
+		next = out.NextLink
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetSecret returns the value of a version of the secret, an empty version reads the current one.
+// A missing secret returns a nil value.
+func (c *Client) GetSecret(ctx context.Context, name string, version string) (*string, error) {
+	var out struct {
+		Value string `json:"value"`
+	}
+	p := secretPath(name)
+	if version != "" {
+		p = secretPath(name, url.PathEscape(version))
+	}
+	if err := c.do(ctx, http.MethodGet, p, nil, &out); err != nil {
+		if NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out.Value, nil
+}
+
+// SetSecret stores the value as a new version of the secret, the secret is created when missing
+func (c *Client) SetSecret(ctx context.Context, name string, value string) error {
+	return c.do(ctx, http.MethodPut, secretPath(name), map[string]string{"value": value}, nil)
+}
+
+// Versions returns the enabled versions of the secret, newest first
+func (c *Client) Versions(ctx context.Context, name string) ([]SecretVersion, error) {
+	var versions []SecretVersion
+	next := secretPath(name, "versions")
+	for next != "" {
+		var out struct {
+			Value    []SecretVersion `json:"value"`
+			NextLink string          `json:"nextLink"`
+		}
+		if err := c.do(ctx, http.MethodGet, next, nil, &out); err != nil {
+			return nil, err
+		}
+		for _, v := range out.Value {
+			if v.Attributes.Enabled {
+				versions = append(versions, v)
+			}
+		}
+		next = out.NextLink
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Attributes.Created > versions[j].Attributes.Created
+	})
+	return versions, nil
+}
+
+// DeleteSecret deletes the secret, with soft delete turned on for the vault it can be recovered until it is purged
+func (c *Client) DeleteSecret(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, secretPath(name), nil, nil)
+}
+
+// RecoverSecret recovers a deleted secret
+func (c *Client) RecoverSecret(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, "deletedsecrets/"+url.PathEscape(name)+"/recover", nil, nil)
+}
+
+// DeletedSecrets returns the deleted secrets of the vault that can still be recovered
+func (c *Client) DeletedSecrets(ctx context.Context) ([]DeletedSecret, error) {
+	var deleted []DeletedSecret
+	next := "deletedsecrets"
+	for next != "" {
+		var out struct {
+			Value    []DeletedSecret `json:"value"`
+			NextLink string          `json:"nextLink"`
+		}
+		if err := c.do(ctx, http.MethodGet, next, nil, &out); err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, out.Value...)
+		next = out.NextLink
+	}
+	return deleted, nil
+}
@@ -0,0 +1,101 @@
+package bitwarden
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Secret is a Bitwarden Secrets Manager secret, listed secrets carry their values as well
+type Secret struct {
+	ID           string    `json:"id"`
+	ProjectID    string    `json:"projectId"`
+	Key          string    `json:"key"`
+	Value        string    `json:"value"`
+	Note         string    `json:"note"`
+	RevisionDate time.Time `json:"revisionDate"`
+}
+
+// BWS runs the bws cli authenticated with the access token of a machine account
+type BWS struct {
+	// Path of the bws binary, bws from PATH when empty
+	Path  string
+	Token string
+	// ServerURL points bws at a self-hosted server, the Bitwarden cloud when empty
+	ServerURL string
+}
+
+// Error is a failed bws call
+type Error struct {
+	Stderr string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("bws: %s", e.Stderr)
+}
+
+// NotFound reports whether bws failed because the secret or project does not exist
+func NotFound(err error) bool {
+	bwsErr, ok := err.(*Error)
+	return ok && strings.Contains(strings.ToLower(bwsErr.Stderr), "not found")
+}
+
+// run runs bws with the arguments and decodes its json output into out when it is not nil, the token is
+// passed in the environment so it never shows up in the process list
+func (b *BWS) run(ctx context.Context, out interface{}, args ...string) error {
+	path := b.Path
+	if path == "" {
+		path = "bws"
+	}
+	c := exec.CommandContext(ctx, path, append(args, "--output", "json", "--color", "no")...)
+	c.Env = append(os.Environ(), "BWS_ACCESS_TOKEN="+b.Token)
+	if b.ServerURL != "" {
+		c.Env = append(c.Env, "BWS_SERVER_URL="+b.ServerURL)
+	}
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return &Error{Stderr: msg}
+		}
+		return fmt.Errorf("running %s: %w", path, err)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(stdout.Bytes(), out)
+}
+
+// List returns the secrets of the project, or every secret the machine account can read without one
+func (b *BWS) List(ctx context.Context, projectID string) ([]Secret, error) {
+	args := []string{"secret", "list"}
+	if projectID != "" {
+		args = append(args, projectID)
+	}
+	var secrets []Secret
+	err := b.run(ctx, &secrets, args...)
+	return secrets, err
+}
+
+// Create creates the secret in the project. bws only takes the value as an argument.
+func (b *BWS) Create(ctx context.Context, key string, value string, projectID string) (Secret, error) {
+	var s Secret
+	err := b.run(ctx, &s, "secret", "create", key, value, projectID)
+	return s, err
+}
+
+// Edit replaces the value of the secret
+func (b *BWS) Edit(ctx context.Context, id string, value string) error {
+	return b.run(ctx, nil, "secret", "edit", id, "--value", value)
+}
+
+// Delete deletes the secrets, Bitwarden does not keep deleted secrets
+func (b *BWS) Delete(ctx context.Context, ids ...string) error {
+	return b.run(ctx, nil, append([]string{"secret", "delete"}, ids...)...)
+}
@@ -0,0 +1,144 @@
+package doppler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultAPI is the address of the Doppler api
+const DefaultAPI = "https://api.doppler.com"
+
+// Client calls the secrets api of one Doppler config
+type Client struct {
+	API     string
+	Token   string
+	Project string
+	Config  string
+	HTTP    *http.Client
+}
+
+// Value is a secret value, Raw is what was stored and Computed has references to other secrets resolved
+type Value struct {
+	Raw      string `json:"raw"`
+	Computed string `json:"computed"`
+}
+
+// Error is an error response from Doppler
+type Error struct {
+	StatusCode int      `json:"-"`
+	Messages   []string `json:"messages"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("doppler responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("doppler responded with status %d: %s", e.StatusCode, strings.Join(e.Messages, ", "))
+}
+
+// NotFound reports whether the error is a 404 response from Doppler
+func NotFound(err error) bool {
+	dopplerErr, ok := err.(*Error)
+	return ok && dopplerErr.StatusCode == http.StatusNotFound
+}
+
+// do sends the request with the project and config added to the query, out is decoded from the json response.
+// Service tokens belong to one config so both can be left empty.
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	if c.Project != "" {
+		query.Set("project", c.Project)
+	}
+	if c.Config != "" {
+		query.Set("config", c.Config)
+	}
+	api := c.API
+	if api == "" {
+		api = DefaultAPI
+	}
+	var reader io.Reader
+	if body != nil {
+		src, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(src)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(api, "/")+path+"?"+query.Encode(), reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	src, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		dopplerErr := &Error{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(src, dopplerErr)
+		return dopplerErr
+	}
+	if out == nil || len(src) == 0 {
+		return nil
+	}
+	return json.Unmarshal(src, out)
+}
+
+// Names returns the sorted names of the secrets of the config
+func (c *Client) Names(ctx context.Context) ([]string, error) {
+	var out struct {
+		Names []string `json:"names"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v3/configs/config/secrets/names", url.Values{"include_dynamic_secrets": {"false"}}, nil, &out); err != nil {
+		return nil, err
+	}
+	sort.Strings(out.Names)
+	return out.Names, nil
+}
+
+// Get returns the value of the secret, nil when it does not exist
+func (c *Client) Get(ctx context.Context, name string) (*Value, error) {
+	var out struct {
+		Value Value `json:"value"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v3/configs/config/secret", url.Values{"name": {name}}, nil, &out); err != nil {
+		if NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out.Value, nil
+}
+
+// Set creates or updates the secrets of the config
+func (c *Client) Set(ctx context.Context, secrets map[string]string) error {
+	return c.do(ctx, http.MethodPost, "/v3/configs/config/secrets", nil, map[string]interface{}{"secrets": secrets}, nil)
+}
+
+// Delete deletes the secret from the config
+func (c *Client) Delete(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/v3/configs/config/secret", url.Values{"name": {name}}, nil, nil)
+}
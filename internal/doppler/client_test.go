@@ -0,0 +1,132 @@
+package doppler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// configServer serves the secrets api for the project and config of a service token
+type configServer struct {
+	mu      sync.Mutex
+	t       *testing.T
+	secrets map[string]string
+}
+
+func newConfigServer(t *testing.T, secrets map[string]string) (*configServer, *Client) {
+	cs := &configServer{t: t, secrets: secrets}
+	srv := httptest.NewServer(cs)
+	t.Cleanup(srv.Close)
+	return cs, &Client{API: srv.URL + "/", Token: "dp.st.token", Project: "app", Config: "prd"}
+}
+
+func (cs *configServer) reply(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (cs *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer dp.st.token" {
+		cs.reply(w, http.StatusUnauthorized, map[string][]string{"messages": {"Invalid Auth token"}})
+		return
+	}
+	query := r.URL.Query()
+	if query.Get("project") != "app" || query.Get("config") != "prd" {
+		cs.t.Errorf("%s %s sent without the project and config", r.Method, r.URL)
+	}
+	name := query.Get("name")
+	switch r.Method + " " + r.URL.Path {
+	case "GET /v3/configs/config/secrets/names":
+		if query.Get("include_dynamic_secrets") != "false" {
+			cs.t.Errorf("names listed with dynamic secrets")
+		}
+		names := []string{}
+		for n := range cs.secrets {
+			names = append(names, n)
+		}
+		cs.reply(w, http.StatusOK, map[string][]string{"names": names})
+	case "GET /v3/configs/config/secret":
+		value, ok := cs.secrets[name]
+		if !ok {
+			cs.reply(w, http.StatusNotFound, map[string][]string{"messages": {"Could not find requested secret: " + name}})
+			return
+		}
+		cs.reply(w, http.StatusOK, map[string]interface{}{"name": name, "value": Value{Raw: value, Computed: value}})
+	case "POST /v3/configs/config/secrets":
+		var body struct {
+			Secrets map[string]string `json:"secrets"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			cs.reply(w, http.StatusBadRequest, map[string][]string{"messages": {err.Error()}})
+			return
+		}
+		for n, v := range body.Secrets {
+			cs.secrets[n] = v
+		}
+		cs.reply(w, http.StatusOK, map[string]interface{}{"secrets": body.Secrets})
+	case "DELETE /v3/configs/config/secret":
+		delete(cs.secrets, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		cs.reply(w, http.StatusNotFound, map[string][]string{})
+	}
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	cs, c := newConfigServer(t, map[string]string{"DB_PASSWORD": "pw", "API_KEY": "key"})
+
+	names, err := c.Names(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"API_KEY", "DB_PASSWORD"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Names = %q, want %q", names, want)
+	}
+
+	value, err := c.Get(ctx, "DB_PASSWORD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value == nil || value.Raw != "pw" || value.Computed != "pw" {
+		t.Errorf("Get = %+v, want pw", value)
+	}
+	if value, err = c.Get(ctx, "MISSING"); err != nil || value != nil {
+		t.Errorf("Get of a missing secret = %+v, %v, want nil, nil", value, err)
+	}
+
+	if err = c.Set(ctx, map[string]string{"DB_PASSWORD": "new", "TOKEN": "t"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Delete(ctx, "API_KEY"); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"DB_PASSWORD": "new", "TOKEN": "t"}; !reflect.DeepEqual(cs.secrets, want) {
+		t.Errorf("secrets = %q, want %q", cs.secrets, want)
+	}
+}
+
+func TestError(t *testing.T) {
+	_, c := newConfigServer(t, map[string]string{})
+	c.Token = "dp.st.wrong"
+	_, err := c.Names(context.Background())
+	if err == nil {
+		t.Fatal("Names with a wrong token succeeded")
+	}
+	if NotFound(err) {
+		t.Error("an auth error reported as not found")
+	}
+	if want := "doppler responded with status 401: Invalid Auth token"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+	if got := (&Error{StatusCode: http.StatusBadGateway}).Error(); got != "doppler responded with status 502" {
+		t.Errorf("error without messages = %q", got)
+	}
+}
@@ -0,0 +1,141 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Kubectl runs kubectl against the current kubeconfig context, Kubeconfig and Context override which one
+type Kubectl struct {
+	// Path of the kubectl binary, kubectl from PATH when empty
+	Path       string
+	Kubeconfig string
+	Context    string
+}
+
+// Error is a failed kubectl call, Reason is the status reason reported by the api server, i.e. NotFound
+type Error struct {
+	Reason string
+	Stderr string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("kubectl: %s", e.Stderr)
+}
+
+// NotFound reports whether the error is a NotFound response from the api server
+func NotFound(err error) bool {
+	kubeErr, ok := err.(*Error)
+	return ok && kubeErr.Reason == "NotFound"
+}
+
+// reasonPattern finds the reason in kubectl errors like: Error from server (NotFound): secrets "app" not found
+var reasonPattern = regexp.MustCompile(`Error from server \((\w+)\)`)
+
+// run runs kubectl with the arguments and returns stdout, stdin is passed to kubectl when not nil
+func (k *Kubectl) run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	path := k.Path
+	if path == "" {
+		path = "kubectl"
+	}
+	if k.Kubeconfig != "" {
+		args = append([]string{"--kubeconfig", k.Kubeconfig}, args...)
+	}
+	if k.Context != "" {
+		args = append([]string{"--context", k.Context}, args...)
+	}
+	c := exec.CommandContext(ctx, path, args...)
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if stdin != nil {
+		c.Stdin = bytes.NewReader(stdin)
+	}
+	if err := c.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return nil, fmt.Errorf("running %s: %w", path, err)
+		}
+		kubeErr := &Error{Stderr: msg}
+		if m := reasonPattern.FindStringSubmatch(msg); m != nil {
+			kubeErr.Reason = m[1]
+		}
+		return nil, kubeErr
+	}
+	return stdout.Bytes(), nil
+}
+
+// Namespace returns the namespace of the kubeconfig context, default when it has none
+func (k *Kubectl) Namespace(ctx context.Context) (string, error) {
+	out, err := k.run(ctx, nil, "config", "view", "--minify", "--output", "jsonpath={..namespace}")
+	if err != nil {
+		return "", err
+	}
+	if ns := strings.TrimSpace(string(out)); ns != "" {
+		return ns, nil
+	}
+	return "default", nil
+}
+
+// List returns the secrets of the namespace
+func (k *Kubectl) List(ctx context.Context, namespace string) ([]Secret, error) {
+	out, err := k.run(ctx, nil, "get", "secrets", "--namespace", namespace, "--output", "json")
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Items []Secret `json:"items"`
+	}
+	if err = json.Unmarshal(out, &list); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// Get returns the secret, nil when it does not exist
+func (k *Kubectl) Get(ctx context.Context, namespace string, name string) (Secret, error) {
+	out, err := k.run(ctx, nil, "get", "secret", name, "--namespace", namespace, "--output", "json")
+	if err != nil {
+		if NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var s Secret
+	if err = json.Unmarshal(out, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Create creates the secret, values are passed on stdin so they never show up in the process list
+func (k *Kubectl) Create(ctx context.Context, s Secret) error {
+	src, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = k.run(ctx, src, "create", "--filename", "-")
+	return err
+}
+
+// Replace replaces the secret, the resourceVersion read with Get makes it fail with a Conflict when the
+// secret changed in between
+func (k *Kubectl) Replace(ctx context.Context, s Secret) error {
+	src, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = k.run(ctx, src, "replace", "--filename", "-")
+	return err
+}
+
+// Delete deletes the secret
+func (k *Kubectl) Delete(ctx context.Context, namespace string, name string) error {
+	_, err := k.run(ctx, nil, "delete", "secret", name, "--namespace", namespace)
+	return err
+}
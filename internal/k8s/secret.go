@@ -0,0 +1,79 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"sort"
+)
+
+// Secret is a Kubernetes Secret object, it is kept as decoded json so replacing it keeps every field
+type Secret map[string]interface{}
+
+// NewSecret returns an empty Opaque secret
+func NewSecret(namespace string, name string) Secret {
+	return Secret{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "Opaque",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"data":       map[string]interface{}{},
+	}
+}
+
+func (s Secret) metadata() map[string]interface{} {
+	m, _ := s["metadata"].(map[string]interface{})
+	return m
+}
+
+// Name returns the name of the secret
+func (s Secret) Name() string {
+	name, _ := s.metadata()["name"].(string)
+	return name
+}
+
+// Type returns the type of the secret, i.e. Opaque
+func (s Secret) Type() string {
+	t, _ := s["type"].(string)
+	return t
+}
+
+func (s Secret) data() map[string]interface{} {
+	d, ok := s["data"].(map[string]interface{})
+	if !ok {
+		d = map[string]interface{}{}
+		s["data"] = d
+	}
+	return d
+}
+
+// Keys returns the sorted keys of the secret data
+func (s Secret) Keys() []string {
+	var keys []string
+	for k := range s.data() {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Value returns the decoded value of the key, ok is false when the key does not exist
+func (s Secret) Value(key string) (string, bool, error) {
+	encoded, ok := s.data()[key].(string)
+	if !ok {
+		return "", false, nil
+	}
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false, err
+	}
+	return string(value), true, nil
+}
+
+// SetValue sets the value of the key
+func (s Secret) SetValue(key string, value string) {
+	s.data()[key] = base64.StdEncoding.EncodeToString([]byte(value))
+}
+
+// DeleteKey removes the key from the secret data
+func (s Secret) DeleteKey(key string) {
+	delete(s.data(), key)
+}
@@ -0,0 +1,61 @@
+package onepassword
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// CLI reads items with the op cli authenticated by a service account token
+type CLI struct {
+	// Path of the op binary, op from PATH when empty
+	Path  string
+	Token string
+}
+
+// run runs op with the arguments and decodes its json output into out
+func (c *CLI) run(ctx context.Context, out interface{}, args ...string) error {
+	path := c.Path
+	if path == "" {
+		path = "op"
+	}
+	cmd := exec.CommandContext(ctx, path, append(args, "--format", "json")...)
+	cmd.Env = append(os.Environ(), "OP_SERVICE_ACCOUNT_TOKEN="+c.Token)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return &Error{Message: msg}
+		}
+		return fmt.Errorf("running %s: %w", path, err)
+	}
+	return json.Unmarshal(stdout.Bytes(), out)
+}
+
+// Vaults lists the vaults the service account can read
+func (c *CLI) Vaults(ctx context.Context) ([]Vault, error) {
+	var vaults []Vault
+	err := c.run(ctx, &vaults, "vault", "list")
+	return vaults, err
+}
+
+// Items lists the items of the vault
+func (c *CLI) Items(ctx context.Context, vaultID string) ([]Item, error) {
+	var items []Item
+	err := c.run(ctx, &items, "item", "list", "--vault", vaultID)
+	return items, err
+}
+
+// Item returns the item with its fields
+func (c *CLI) Item(ctx context.Context, vaultID string, itemID string) (*Item, error) {
+	var item Item
+	if err := c.run(ctx, &item, "item", "get", itemID, "--vault", vaultID); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
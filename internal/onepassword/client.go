@@ -0,0 +1,46 @@
+package onepassword
+
+import (
+	"context"
+	"fmt"
+)
+
+// Vault is a 1Password vault
+type Vault struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Field is a field of an item
+type Field struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// Item is a 1Password item, items listed from a vault have no fields
+type Item struct {
+	ID     string  `json:"id"`
+	Title  string  `json:"title"`
+	Fields []Field `json:"fields"`
+}
+
+// Client reads vaults and items from 1Password
+type Client interface {
+	Vaults(ctx context.Context) ([]Vault, error)
+	Items(ctx context.Context, vaultID string) ([]Item, error)
+	Item(ctx context.Context, vaultID string, itemID string) (*Item, error)
+}
+
+// Error is an error response from Connect or the op cli, StatusCode is 0 for the cli
+type Error struct {
+	StatusCode int    `json:"status"`
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("1password: %s", e.Message)
+	}
+	return fmt.Sprintf("1password responded with status %d: %s", e.StatusCode, e.Message)
+}
@@ -0,0 +1,69 @@
+package onepassword
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Connect reads items through a 1Password Connect server
+type Connect struct {
+	Host  string
+	Token string
+	HTTP  *http.Client
+}
+
+// get sends a GET request to the Connect api and decodes the json response into out
+func (c *Connect) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.Host, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	src, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		connectErr := &Error{}
+		_ = json.Unmarshal(src, connectErr)
+		connectErr.StatusCode = resp.StatusCode
+		return connectErr
+	}
+	return json.Unmarshal(src, out)
+}
+
+// Vaults lists the vaults the token can read
+func (c *Connect) Vaults(ctx context.Context) ([]Vault, error) {
+	var vaults []Vault
+	err := c.get(ctx, "/v1/vaults", &vaults)
+	return vaults, err
+}
+
+// Items lists the items of the vault
+func (c *Connect) Items(ctx context.Context, vaultID string) ([]Item, error) {
+	var items []Item
+	err := c.get(ctx, "/v1/vaults/"+url.PathEscape(vaultID)+"/items", &items)
+	return items, err
+}
+
+// Item returns the item with its fields
+func (c *Connect) Item(ctx context.Context, vaultID string, itemID string) (*Item, error) {
+	var item Item
+	if err := c.get(ctx, "/v1/vaults/"+url.PathEscape(vaultID)+"/items/"+url.PathEscape(itemID), &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Protocol is the version of the plugin protocol sent with every request
+const Protocol = 1
+
+// Operations a plugin can be asked to run, a plugin answers the ones it does not support with an unsupported error
+const (
+	OpList     = "list"
+	OpGet      = "get"
+	OpSet      = "set"
+	OpDelete   = "delete"
+	OpRestore  = "restore"
+	OpRollback = "rollback"
+)
+
+// Plugin runs a provider plugin, an executable that answers one request per run. jaws starts it with the
+// operation as its only argument, writes the request as json to stdin and reads the response from stdout.
+type Plugin struct {
+	Path    string
+	Profile string
+	// Config is passed to the plugin with every request, i.e. the path of a keepass database
+	Config map[string]string
+}
+
+// Secret is a secret sent to or returned by a plugin, list responses leave Value empty
+type Secret struct {
+	ID       string            `json:"id"`
+	Value    string            `json:"value,omitempty"`
+	Version  string            `json:"version,omitempty"`
+	Modified *time.Time        `json:"modified,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// Request is the json written to the stdin of the plugin
+type Request struct {
+	Protocol int               `json:"protocol"`
+	Profile  string            `json:"profile"`
+	Config   map[string]string `json:"config,omitempty"`
+	// IDs are the secrets to get, delete, restore or roll back
+	IDs []string `json:"ids,omitempty"`
+	// Secrets are the secrets to set
+	Secrets []Secret `json:"secrets,omitempty"`
+	// Days is how long a delete is recoverable for, plugins without scheduled deletions ignore it
+	Days int64 `json:"days,omitempty"`
+	// Steps is how many versions a rollback goes back
+	Steps int `json:"steps,omitempty"`
+}
+
+// Response is the json the plugin writes to stdout, a failed request sets Error and optionally Kind
+type Response struct {
+	Secrets []Secret `json:"secrets,omitempty"`
+	Error   string   `json:"error,omitempty"`
+	// Kind is one of auth, not_found, conflict, validation, network or unsupported
+	Kind string `json:"kind,omitempty"`
+}
+
+// Error is a failed request, either answered by the plugin or a plugin that exited without a response
+type Error struct {
+	Kind    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Call runs the operation and returns the secrets of the response
+func (p *Plugin) Call(ctx context.Context, op string, req Request) ([]Secret, error) {
+	req.Protocol = Protocol
+	req.Profile = p.Profile
+	req.Config = p.Config
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	c := exec.CommandContext(ctx, p.Path, op)
+	var stdout, stderr bytes.Buffer
+	c.Stdin = bytes.NewReader(in)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	runErr := c.Run()
+
+	var resp Response
+	if err = json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if runErr != nil && msg != "" {
+			return nil, &Error{Message: fmt.Sprintf("plugin %s %s: %s", p.Path, op, msg)}
+		}
+		if runErr != nil {
+			return nil, fmt.Errorf("running plugin %s %s: %w", p.Path, op, runErr)
+		}
+		return nil, fmt.Errorf("plugin %s %s: reading response: %w", p.Path, op, err)
+	}
+	if resp.Error != "" {
+		return nil, &Error{Kind: resp.Kind, Message: fmt.Sprintf("plugin %s %s: %s", p.Path, op, resp.Error)}
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("running plugin %s %s: %w", p.Path, op, runErr)
+	}
+	return resp.Secrets, nil
+}
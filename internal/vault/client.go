@@ -0,0 +1,107 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the HTTP API of a Vault server
+type Client struct {
+	Address   string
+	Namespace string
+	Token     string
+	HTTP      *http.Client
+}
+
+// Error is an error response from Vault
+type Error struct {
+	StatusCode int      `json:"-"`
+	Errors     []string `json:"errors"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("vault responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("vault responded with status %d: %s", e.StatusCode, strings.Join(e.Errors, ", "))
+}
+
+// NotFound reports whether the error is a 404 response from Vault
+func NotFound(err error) bool {
+	vaultErr, ok := err.(*Error)
+	return ok && vaultErr.StatusCode == http.StatusNotFound
+}
+
+// do sends the request with the body encoded as json and decodes the response into out when it is not nil
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) error {
+	u := strings.TrimSuffix(c.Address, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		src, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(src)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Vault-Token", c.Token)
+	}
+	if c.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	src, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		vaultErr := &Error{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(src, vaultErr)
+		return vaultErr
+	}
+	if out == nil || len(src) == 0 {
+		return nil
+	}
+	return json.Unmarshal(src, out)
+}
+
+// Login authenticates against the auth method mounted at authMount, i.e. approle or kubernetes, and returns the client token
+func (c *Client) Login(ctx context.Context, authMount string, payload map[string]string) (string, error) {
+	var out struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := c.do(ctx, http.MethodPost, "auth/"+strings.Trim(authMount, "/")+"/login", nil, payload, &out); err != nil {
+		return "", fmt.Errorf("vault login with %s: %w", authMount, err)
+	}
+	if out.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault login with %s returned no token", authMount)
+	}
+	return out.Auth.ClientToken, nil
+}
@@ -0,0 +1,131 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the metadata of one version of a KV v2 secret
+type Version struct {
+	CreatedTime  time.Time `json:"created_time"`
+	DeletionTime string    `json:"deletion_time"`
+	Destroyed    bool      `json:"destroyed"`
+}
+
+// Live reports whether the version can still be read
+func (v Version) Live() bool {
+	return v.DeletionTime == "" && !v.Destroyed
+}
+
+// Metadata is the metadata of a KV v2 secret
+type Metadata struct {
+	CurrentVersion int                `json:"current_version"`
+	Versions       map[string]Version `json:"versions"`
+}
+
+// LiveVersions returns the numbers of the versions that can still be read, newest first
+func (m Metadata) LiveVersions() []int {
+	var versions []int
+	for n, v := range m.Versions {
+		number, err := strconv.Atoi(n)
+		if err != nil || !v.Live() {
+			continue
+		}
+		versions = append(versions, number)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	return versions
+}
+
+func dataPath(mount string, kind string, path string) string {
+	return strings.Trim(mount, "/") + "/" + kind + "/" + strings.TrimPrefix(path, "/")
+}
+
+// Read returns the data of the version of the secret, version 0 reads the current version. Deleted versions
+// and missing secrets return a nil map.
+func (c *Client) Read(ctx context.Context, mount string, path string, version int) (map[string]interface{}, error) {
+	var query url.Values
+	if version > 0 {
+		query = url.Values{"version": {strconv.Itoa(version)}}
+	}
+	var out struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, dataPath(mount, "data", path), query, nil, &out); err != nil {
+		if NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out.Data.Data, nil
+}
+
+// Write stores data as a new version of the secret, cas is the version the write expects to replace
+// with 0 only allowing the secret to be created. A nil cas writes unconditionally.
+func (c *Client) Write(ctx context.Context, mount string, path string, data map[string]interface{}, cas *int) error {
+	body := map[string]interface{}{"data": data}
+	if cas != nil {
+		body["options"] = map[string]int{"cas": *cas}
+	}
+	return c.do(ctx, http.MethodPost, dataPath(mount, "data", path), nil, body, nil)
+}
+
+// Metadata returns the versions of the secret, nil when the secret does not exist
+func (c *Client) Metadata(ctx context.Context, mount string, path string) (*Metadata, error) {
+	var out struct {
+		Data Metadata `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, dataPath(mount, "metadata", path), nil, nil, &out); err != nil {
+		if NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out.Data, nil
+}
+
+// List returns every secret under the path, folders are listed recursively
+func (c *Client) List(ctx context.Context, mount string, path string) ([]string, error) {
+	var out struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, dataPath(mount, "metadata", path), url.Values{"list": {"true"}}, nil, &out)
+	if err != nil {
+		if NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var secrets []string
+	for _, key := range out.Data.Keys {
+		if strings.HasSuffix(key, "/") {
+			nested, err := c.List(ctx, mount, path+key)
+			if err != nil {
+				return nil, err
+			}
+			secrets = append(secrets, nested...)
+			continue
+		}
+		secrets = append(secrets, path+key)
+	}
+	return secrets, nil
+}
+
+// Delete soft deletes the current version of the secret, it can be brought back with Undelete
+func (c *Client) Delete(ctx context.Context, mount string, path string) error {
+	return c.do(ctx, http.MethodDelete, dataPath(mount, "data", path), nil, nil, nil)
+}
+
+// Undelete restores soft deleted versions of the secret
+func (c *Client) Undelete(ctx context.Context, mount string, path string, versions []int) error {
+	return c.do(ctx, http.MethodPost, dataPath(mount, "undelete", path), nil, map[string][]int{"versions": versions}, nil)
+}
@@ -0,0 +1,269 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// kvServer is a KV v2 engine mounted at secret/ keeping every version in memory
+type kvServer struct {
+	mu      sync.Mutex
+	token   string
+	secrets map[string][]kvVersion
+}
+
+type kvVersion struct {
+	data    map[string]interface{}
+	deleted bool
+}
+
+func newKVServer(t *testing.T, token string) (*kvServer, *Client) {
+	kv := &kvServer{token: token, secrets: map[string][]kvVersion{}}
+	srv := httptest.NewServer(kv)
+	t.Cleanup(srv.Close)
+	return kv, &Client{Address: srv.URL + "/", Namespace: "team", Token: token}
+}
+
+func (kv *kvServer) reply(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if body != nil {
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
+
+func (kv *kvServer) fail(w http.ResponseWriter, status int, msg ...string) {
+	kv.reply(w, status, map[string][]string{"errors": msg})
+}
+
+func (kv *kvServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	if r.Header.Get("X-Vault-Token") != kv.token {
+		kv.fail(w, http.StatusForbidden, "permission denied")
+		return
+	}
+	if r.Header.Get("X-Vault-Namespace") != "team" {
+		kv.fail(w, http.StatusBadRequest, "missing namespace")
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/v1/secret/")
+	kind, name := path, ""
+	if i := strings.Index(path, "/"); i != -1 {
+		kind, name = path[:i], path[i+1:]
+	}
+	switch {
+	case kind == "data" && r.Method == http.MethodGet:
+		versions := kv.secrets[name]
+		n := len(versions)
+		if v := r.URL.Query().Get("version"); v != "" {
+			n, _ = strconv.Atoi(v)
+		}
+		if n == 0 || n > len(versions) || versions[n-1].deleted {
+			kv.reply(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+			return
+		}
+		kv.reply(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"data": versions[n-1].data}})
+	case kind == "data" && r.Method == http.MethodPost:
+		var body struct {
+			Data    map[string]interface{} `json:"data"`
+			Options *struct {
+				CAS int `json:"cas"`
+			} `json:"options"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			kv.fail(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if body.Options != nil && body.Options.CAS != len(kv.secrets[name]) {
+			kv.fail(w, http.StatusBadRequest, "check-and-set parameter did not match the current version")
+			return
+		}
+		kv.secrets[name] = append(kv.secrets[name], kvVersion{data: body.Data})
+		kv.reply(w, http.StatusOK, nil)
+	case kind == "data" && r.Method == http.MethodDelete:
+		if versions := kv.secrets[name]; len(versions) != 0 {
+			versions[len(versions)-1].deleted = true
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case kind == "undelete" && r.Method == http.MethodPost:
+		var body struct {
+			Versions []int `json:"versions"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for _, n := range body.Versions {
+			kv.secrets[name][n-1].deleted = false
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case kind == "metadata" && r.URL.Query().Get("list") == "true":
+		keys := map[string]bool{}
+		for secret := range kv.secrets {
+			if !strings.HasPrefix(secret, name) {
+				continue
+			}
+			rest := strings.TrimPrefix(secret, name)
+			if i := strings.Index(rest, "/"); i != -1 {
+				rest = rest[:i+1]
+			}
+			keys[rest] = true
+		}
+		if len(keys) == 0 {
+			kv.reply(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+			return
+		}
+		var list []string
+		for k := range keys {
+			list = append(list, k)
+		}
+		sort.Strings(list)
+		kv.reply(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"keys": list}})
+	case kind == "metadata":
+		versions := kv.secrets[name]
+		if len(versions) == 0 {
+			kv.reply(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+			return
+		}
+		meta := map[string]interface{}{}
+		for i, v := range versions {
+			deletion := ""
+			if v.deleted {
+				deletion = time.Now().UTC().Format(time.RFC3339)
+			}
+			meta[strconv.Itoa(i+1)] = map[string]interface{}{"created_time": time.Now().UTC().Format(time.RFC3339), "deletion_time": deletion, "destroyed": false}
+		}
+		kv.reply(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"current_version": len(versions), "versions": meta}})
+	default:
+		kv.fail(w, http.StatusMethodNotAllowed, "unsupported "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func TestKV(t *testing.T) {
+	ctx := context.Background()
+	_, c := newKVServer(t, "s.token")
+
+	if data, err := c.Read(ctx, "secret", "app/db", 0); err != nil || data != nil {
+		t.Fatalf("Read of a missing secret = %v, %v, want nil, nil", data, err)
+	}
+	if meta, err := c.Metadata(ctx, "/secret/", "app/db"); err != nil || meta != nil {
+		t.Fatalf("Metadata of a missing secret = %v, %v, want nil, nil", meta, err)
+	}
+
+	create := 0
+	if err := c.Write(ctx, "secret", "app/db", map[string]interface{}{"value": "one"}, &create); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write(ctx, "secret", "app/db", map[string]interface{}{"value": "stale"}, &create); err == nil {
+		t.Fatal("Write with an outdated cas succeeded")
+	} else if vaultErr, ok := err.(*Error); !ok || vaultErr.StatusCode != http.StatusBadRequest || !strings.Contains(err.Error(), "check-and-set") {
+		t.Fatalf("Write with an outdated cas error = %v", err)
+	}
+	if err := c.Write(ctx, "secret", "app/db", map[string]interface{}{"value": "two"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write(ctx, "secret", "app/api/key", map[string]interface{}{"value": "k"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := c.Read(ctx, "secret", "app/db", 0); err != nil || data["value"] != "two" {
+		t.Fatalf("Read = %v, %v, want value two", data, err)
+	}
+	if data, err := c.Read(ctx, "secret", "app/db", 1); err != nil || data["value"] != "one" {
+		t.Fatalf("Read of version 1 = %v, %v, want value one", data, err)
+	}
+
+	names, err := c.List(ctx, "secret", "app/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"app/api/key", "app/db"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("List = %q, want %q", names, want)
+	}
+	if names, err := c.List(ctx, "secret", "other/"); err != nil || names != nil {
+		t.Errorf("List of an empty folder = %q, %v, want nil, nil", names, err)
+	}
+
+	if err = c.Delete(ctx, "secret", "app/db"); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := c.Metadata(ctx, "secret", "app/db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.CurrentVersion != 2 {
+		t.Errorf("CurrentVersion = %d, want 2", meta.CurrentVersion)
+	}
+	if got, want := meta.LiveVersions(), []int{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LiveVersions after delete = %v, want %v", got, want)
+	}
+	if data, err := c.Read(ctx, "secret", "app/db", 0); err != nil || data != nil {
+		t.Errorf("Read of a deleted version = %v, %v, want nil, nil", data, err)
+	}
+
+	if err = c.Undelete(ctx, "secret", "app/db", []int{2}); err != nil {
+		t.Fatal(err)
+	}
+	if meta, err = c.Metadata(ctx, "secret", "app/db"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := meta.LiveVersions(), []int{2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LiveVersions after undelete = %v, want %v", got, want)
+	}
+}
+
+func TestError(t *testing.T) {
+	_, c := newKVServer(t, "s.token")
+	c.Token = "s.wrong"
+	_, err := c.Read(context.Background(), "secret", "app/db", 0)
+	if err == nil {
+		t.Fatal("Read with a wrong token succeeded")
+	}
+	if NotFound(err) {
+		t.Error("a permission error reported as not found")
+	}
+	if want := "vault responded with status 403: permission denied"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+	if got := (&Error{StatusCode: http.StatusBadGateway}).Error(); got != "vault responded with status 502" {
+		t.Errorf("error without messages = %q", got)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		switch {
+		case r.URL.Path != "/v1/auth/approle/login":
+			w.WriteHeader(http.StatusNotFound)
+		case payload["role_id"] == "role" && payload["secret_id"] == "secret":
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"s.issued"}}`))
+		case payload["role_id"] == "empty":
+			_, _ = w.Write([]byte(`{"auth":{}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+		}
+	}))
+	defer srv.Close()
+	c := &Client{Address: srv.URL}
+
+	token, err := c.Login(context.Background(), "/approle/", map[string]string{"role_id": "role", "secret_id": "secret"})
+	if err != nil || token != "s.issued" {
+		t.Fatalf("Login = %q, %v, want s.issued", token, err)
+	}
+	if _, err = c.Login(context.Background(), "approle", map[string]string{"role_id": "role", "secret_id": "wrong"}); err == nil || !strings.Contains(err.Error(), "invalid role or secret ID") {
+		t.Errorf("Login with a wrong secret error = %v", err)
+	}
+	if _, err = c.Login(context.Background(), "approle", map[string]string{"role_id": "empty"}); err == nil {
+		t.Error("Login without a token in the response succeeded")
+	}
+}
@@ -0,0 +1,50 @@
+package vault
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// TLSConfig trusts a private CA and presents a client certificate, for servers behind a corporate PKI or
+// requiring mTLS
+type TLSConfig struct {
+	CACert     string
+	ClientCert string
+	ClientKey  string
+	ServerName string
+}
+
+// HTTPClient returns a client using the TLS config, nil when nothing is set so the default client is used
+func (t TLSConfig) HTTPClient() (*http.Client, error) {
+	if t == (TLSConfig{}) {
+		return nil, nil
+	}
+	config := &tls.Config{ServerName: t.ServerName, MinVersion: tls.VersionTLS12}
+	if t.CACert != "" {
+		pem, err := ioutil.ReadFile(t.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("reading ca_cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_cert %s holds no PEM certificates", t.CACert)
+		}
+		config.RootCAs = pool
+	}
+	if (t.ClientCert == "") != (t.ClientKey == "") {
+		return nil, fmt.Errorf("client_cert and client_key have to be set together")
+	}
+	if t.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,343 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// DefaultAgentTTL is how long the agent serves a secret list before listing the profile again
+const DefaultAgentTTL = 5 * time.Minute
+
+// agent is the running jaws agent listings and pulls go through, nil when there is none
+var agent *agentClient
+
+// AgentSocket returns the socket of the jaws agent, JAWS_AGENT_SOCK or agent.sock in the state directory
+func AgentSocket() string {
+	if socket := os.Getenv("JAWS_AGENT_SOCK"); socket != "" {
+		return socket
+	}
+	return filepath.Join(helpers.StateDir(), "agent.sock")
+}
+
+// agentRequest is one request to the agent, every connection carries one request and its response
+type agentRequest struct {
+	// Op is ping, list, get or forget
+	Op string `json:"op"`
+	// Config is the config file of the client, the agent only answers clients using its own config
+	Config  string      `json:"config"`
+	Profile string      `json:"profile,omitempty"`
+	Refs    []string    `json:"refs,omitempty"`
+	Query   *agentQuery `json:"query,omitempty"`
+}
+
+// agentQuery is a Query with the regex as a string
+type agentQuery struct {
+	Prefixes   []string          `json:"prefixes,omitempty"`
+	IDs        []string          `json:"ids,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Regex      string            `json:"regex,omitempty"`
+	MaxResults int               `json:"max_results,omitempty"`
+}
+
+type agentSecret struct {
+	ID        string `json:"id"`
+	Content   string `json:"content"`
+	Binary    []byte `json:"binary,omitempty"`
+	Recipient string `json:"recipient,omitempty"`
+	Profile   string `json:"profile,omitempty"`
+}
+
+type agentResponse struct {
+	Infos   []SecretInfo  `json:"infos,omitempty"`
+	Secrets []agentSecret `json:"secrets,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Kind    ErrorKind     `json:"kind,omitempty"`
+}
+
+// err returns the error the agent answered with
+func (r agentResponse) err() error {
+	if r.Error == "" {
+		return nil
+	}
+	return Errorf(r.Kind, "agent: %s", r.Error)
+}
+
+// Agent keeps the managers of a config and their secret lists in memory and serves listings and pulls
+// over a unix socket, so repeated runs of jaws neither authenticate again nor page through every secret
+type Agent struct {
+	// Config is the config file the managers were read from
+	Config       string
+	Managers     []Manager
+	ProfileOrder []string
+	// TTL is how long a secret list is served before the profile is listed again
+	TTL time.Duration
+
+	mu       sync.Mutex
+	modified time.Time
+	lists    map[string]agentList
+}
+
+type agentList struct {
+	infos   []SecretInfo
+	expires time.Time
+}
+
+// Serve listens on the socket until the context is canceled, the socket is removed when it returns. A socket
+// left behind by an agent that is gone is replaced.
+func (a *Agent) Serve(ctx context.Context, socket string) error {
+	if a.TTL <= 0 {
+		a.TTL = DefaultAgentTTL
+	}
+	if info, err := os.Stat(a.Config); err == nil {
+		a.modified = info.ModTime()
+	}
+	if conn, err := net.Dial("unix", socket); err == nil {
+		conn.Close()
+		return Errorf(KindConflict, "an agent is already listening on %s", socket)
+	}
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		return err
+	}
+	_ = os.Remove(socket)
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socket)
+	if err = os.Chmod(socket, 0600); err != nil {
+		l.Close()
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go a.handle(conn)
+	}
+}
+
+func (a *Agent) handle(conn net.Conn) {
+	defer helpers.HandlePanic()
+	defer conn.Close()
+	var req agentRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		helpers.Verbosef(helpers.VerbosityVerbose, "agent: reading request: %v\n", err)
+		return
+	}
+	helpers.Verbosef(helpers.VerbosityDebug, "agent: %s %s %v\n", req.Op, req.Profile, req.Refs)
+	if err := json.NewEncoder(conn).Encode(a.answer(req)); err != nil {
+		helpers.Verbosef(helpers.VerbosityVerbose, "agent: writing response: %v\n", err)
+	}
+}
+
+// answer runs the request, requests are answered one at a time
+func (a *Agent) answer(req agentRequest) agentResponse {
+	if req.Config != a.Config {
+		return agentResponse{Error: fmt.Sprintf("serving config %s, not %s", a.Config, req.Config), Kind: KindConflict}
+	}
+	if info, err := os.Stat(a.Config); err == nil && !info.ModTime().Equal(a.modified) {
+		return agentResponse{Error: "the config changed since the agent started, restart it", Kind: KindConflict}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch req.Op {
+	case "ping":
+		return agentResponse{}
+	case "forget":
+		a.lists = nil
+		return agentResponse{}
+	case "list":
+		infos, err := a.list(req.Profile, req.Query)
+		if err != nil {
+			return agentResponse{Error: err.Error(), Kind: KindOf(err)}
+		}
+		return agentResponse{Infos: infos}
+	case "get":
+		return a.get(req.Profile, req.Refs)
+	}
+	return agentResponse{Error: fmt.Sprintf("unknown request %s", req.Op), Kind: KindValidation}
+}
+
+// list filters the cached secret list of the profile, it is listed again once the TTL passed
+func (a *Agent) list(profile string, aq *agentQuery) ([]SecretInfo, error) {
+	var q Query
+	if aq != nil {
+		q = Query{Prefixes: aq.Prefixes, IDs: aq.IDs, Tags: aq.Tags, MaxResults: aq.MaxResults}
+		if aq.Regex != "" {
+			re, err := regexp.Compile(aq.Regex)
+			if err != nil {
+				return nil, Errorf(KindValidation, "invalid regex: %w", err)
+			}
+			q.Regex = re
+		}
+	}
+	cached, ok := a.lists[profile]
+	if !ok || time.Now().After(cached.expires) {
+		m, err := FindManager(a.Managers, profile)
+		if err != nil {
+			return nil, err
+		}
+		infos, err := ListInfo(m)
+		if err != nil {
+			return nil, err
+		}
+		cached = agentList{infos: infos, expires: time.Now().Add(a.TTL)}
+		if a.lists == nil {
+			a.lists = map[string]agentList{}
+		}
+		a.lists[profile] = cached
+	}
+	var matched []SecretInfo
+	for _, info := range cached.infos {
+		if !q.Match(info) {
+			continue
+		}
+		matched = append(matched, info)
+		if q.MaxResults > 0 && len(matched) >= q.MaxResults {
+			break
+		}
+	}
+	return matched, nil
+}
+
+// get pulls the secrets, values are never kept between requests
+func (a *Agent) get(profile string, refs []string) agentResponse {
+	m, err := FindManager(a.Managers, profile)
+	if err != nil {
+		return agentResponse{Error: err.Error(), Kind: KindOf(err)}
+	}
+	for _, m := range a.Managers {
+		if aws, ok := m.(*AWSManager); ok {
+			aws.cache.reset()
+		}
+	}
+	Secrets, err := GetPinned(a.Managers, m, refs, a.ProfileOrder)
+	var resp agentResponse
+	for _, s := range Secrets {
+		resp.Secrets = append(resp.Secrets, agentSecret{ID: s.ID, Content: s.Content, Binary: s.Binary, Recipient: s.recipient, Profile: s.profile})
+	}
+	if err != nil {
+		resp.Error, resp.Kind = err.Error(), KindOf(err)
+	}
+	return resp
+}
+
+// agentClient sends requests to a running agent
+type agentClient struct {
+	socket string
+	config string
+}
+
+// UseAgent sends the listings and pulls of this run to the jaws agent on the socket, it reports whether an
+// agent serving the config answered
+func UseAgent(socket string, config string) bool {
+	if config == "" {
+		return false
+	}
+	c := &agentClient{socket: socket, config: config}
+	resp, err := c.call(agentRequest{Op: "ping"})
+	if err != nil {
+		helpers.Verbosef(helpers.VerbosityDebug, "not using the agent: %v\n", err)
+		return false
+	}
+	if resp.Error != "" {
+		helpers.Verbosef(helpers.VerbosityVerbose, "not using the agent on %s: %s\n", socket, resp.Error)
+		return false
+	}
+	helpers.Verbosef(helpers.VerbosityVerbose, "using the agent on %s\n", socket)
+	agent = c
+	return true
+}
+
+// call sends the request, the error is only set when the agent could not be reached
+func (c *agentClient) call(req agentRequest) (agentResponse, error) {
+	var resp agentResponse
+	req.Config = c.config
+	conn, err := net.DialTimeout("unix", c.socket, time.Second)
+	if err != nil {
+		return resp, err
+	}
+	defer conn.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// closing the connection ends a request that is still running when jaws is interrupted
+		select {
+		case <-helpers.Context().Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	if err = json.NewEncoder(conn).Encode(req); err != nil {
+		return resp, err
+	}
+	err = json.NewDecoder(conn).Decode(&resp)
+	return resp, err
+}
+
+// listQuery lists the secrets of the profile through the agent, served is false when there is no agent to ask
+func (c *agentClient) listQuery(profile string, q Query) (infos []SecretInfo, served bool, err error) {
+	if c == nil {
+		return nil, false, nil
+	}
+	aq := &agentQuery{Prefixes: q.Prefixes, IDs: q.IDs, Tags: q.Tags, MaxResults: q.MaxResults}
+	if q.Regex != nil {
+		aq.Regex = q.Regex.String()
+	}
+	resp, err := c.call(agentRequest{Op: "list", Profile: profile, Query: aq})
+	if err != nil {
+		helpers.Verbosef(helpers.VerbosityVerbose, "agent unreachable, listing %s directly: %v\n", profile, err)
+		return nil, false, nil
+	}
+	return resp.Infos, true, resp.err()
+}
+
+// getPinned pulls the secrets through the agent, served is false when there is no agent to ask
+func (c *agentClient) getPinned(profile string, refs []string) (Secrets []Secret, served bool, err error) {
+	if c == nil {
+		return nil, false, nil
+	}
+	resp, err := c.call(agentRequest{Op: "get", Profile: profile, Refs: refs})
+	if err != nil {
+		if helpers.Interrupted() {
+			return nil, true, &Interrupted{Op: "pull", Aborted: refs}
+		}
+		helpers.Verbosef(helpers.VerbosityVerbose, "agent unreachable, pulling directly: %v\n", err)
+		return nil, false, nil
+	}
+	for _, s := range resp.Secrets {
+		if s.Binary == nil {
+			helpers.AddRedaction(s.Content)
+		}
+		helpers.Progress(helpers.ProgressEvent{Event: helpers.EventSecretPulled, Profile: s.Profile, Secret: s.ID})
+		Secrets = append(Secrets, Secret{ID: s.ID, Content: s.Content, Binary: s.Binary, recipient: s.Recipient, profile: s.Profile})
+	}
+	return Secrets, true, resp.err()
+}
+
+// forget drops the secret lists of the agent, called after secrets changed
+func (c *agentClient) forget() {
+	if c == nil {
+		return
+	}
+	if _, err := c.call(agentRequest{Op: "forget"}); err != nil {
+		helpers.Verbosef(helpers.VerbosityVerbose, "agent unreachable, its secret lists may be stale: %v\n", err)
+	}
+}
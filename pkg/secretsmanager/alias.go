@@ -0,0 +1,33 @@
+package secretsmanager
+
+import (
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// AliasHCL names a jaws command line, i.e. alias "deploy-env" { command = "get @payments-prod --path deploy" }
+type AliasHCL struct {
+	Name    string `hcl:"name,label"`
+	Command string `hcl:"command"`
+}
+
+// FindAlias returns the alias with the name, nil when there is none
+func FindAlias(aliases []AliasHCL, name string) *AliasHCL {
+	for i := range aliases {
+		if aliases[i].Name == name {
+			return &aliases[i]
+		}
+	}
+	return nil
+}
+
+// Args splits the command of the alias into the arguments passed to jaws
+func (a AliasHCL) Args() ([]string, error) {
+	args, err := helpers.ShellSplit(a.Command)
+	if err != nil {
+		return nil, Errorf(KindValidation, "alias %s: %w", a.Name, err)
+	}
+	if len(args) == 0 {
+		return nil, Errorf(KindValidation, "alias %s has an empty command", a.Name)
+	}
+	return args, nil
+}
@@ -0,0 +1,420 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/internal/azure"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// azureNamePattern matches the names Key Vault accepts for secrets
+var azureNamePattern = regexp.MustCompile(`^[0-9a-zA-Z-]{1,127}$`)
+
+// AzureManager stores secrets in an Azure Key Vault. Key Vault names can not hold a /, secret IDs are stored
+// with every / replaced by the separator, -- by default.
+type AzureManager struct {
+	Profile      string
+	Vault        string `hcl:"vault"`
+	TenantID     string `hcl:"tenant_id,optional"`
+	ClientID     string `hcl:"client_id,optional"`
+	ClientSecret string `hcl:"client_secret,optional"`
+	Separator    string `hcl:"separator,optional"`
+	AgeRecipient string `hcl:"age_recipient,optional"`
+	client       *azure.Client
+}
+
+// loadClient returns the Key Vault client, a client secret from the config or AZURE_CLIENT_SECRET authenticates as
+// a service principal and the managed identity is used otherwise
+func (z *AzureManager) loadClient() (*azure.Client, error) {
+	if z.client != nil {
+		return z.client, nil
+	}
+	helpers.Verbosef(helpers.VerbosityDebug, "loading azure client for profile %s\n", z.Profile)
+	creds := &azure.Credentials{
+		TenantID:     firstNonEmpty(z.TenantID, os.Getenv("AZURE_TENANT_ID")),
+		ClientID:     firstNonEmpty(z.ClientID, os.Getenv("AZURE_CLIENT_ID")),
+		ClientSecret: firstNonEmpty(z.ClientSecret, os.Getenv("AZURE_CLIENT_SECRET")),
+	}
+	if creds.ClientSecret != "" && (creds.TenantID == "" || creds.ClientID == "") {
+		return nil, Errorf(KindValidation, "azure manager %s needs tenant_id and client_id with a client secret", z.Profile)
+	}
+	helpers.AddRedaction(creds.ClientSecret)
+	vaultURL := z.Vault
+	if !strings.HasPrefix(vaultURL, "https://") {
+		vaultURL = fmt.Sprintf("https://%s.vault.azure.net", vaultURL)
+	}
+	z.client = &azure.Client{VaultURL: vaultURL, Credentials: creds}
+	return z.client, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (z *AzureManager) separator() string {
+	if z.Separator == "" {
+		return "--"
+	}
+	return z.Separator
+}
+
+// name returns the Key Vault name of the secret ID
+func (z *AzureManager) name(secretID string) (string, error) {
+	name := strings.ReplaceAll(secretID, "/", z.separator())
+	if !azureNamePattern.MatchString(name) {
+		return "", Errorf(KindValidation, "%s can not be stored in azure key vault as %s, only letters, digits and - are allowed", secretID, name)
+	}
+	return name, nil
+}
+
+// secretID returns the secret ID of the Key Vault name
+func (z *AzureManager) secretID(name string) string {
+	return strings.ReplaceAll(name, z.separator(), "/")
+}
+
+// AzureManager Create
+func (z *AzureManager) Create(args []string, secretsPath string, useEditor bool) error {
+	return createLocalSecret(args, secretsPath, useEditor)
+}
+
+// AzureManager Delete deletes the selected secrets, the vault soft delete retention decides how long they
+// can be recovered so the number of days is not used
+func (z *AzureManager) Delete(scheduleInDays int64) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := z.loadClient()
+	if err != nil {
+		return err
+	}
+	sID, err := z.FuzzyFind(ctx)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("delete", z.Profile, sID); err != nil {
+		return err
+	}
+
+	var deleted []string
+	defer func() { notify("delete", z.Profile, deleted) }()
+	for _, id := range sID {
+		name, err := z.name(id)
+		if err != nil {
+			return err
+		}
+		timeCtx, cancelDelete := context.WithTimeout(ctx, helpers.PushTimeout)
+		err = client.DeleteSecret(timeCtx, name)
+		cancelDelete()
+		if err != nil {
+			return err
+		}
+		deleted = append(deleted, id)
+		helpers.Infof("%s %s\n", id, color.RedString("deleted"))
+	}
+	return nil
+}
+
+// AzureManager DeleteCancel recovers the given deleted secrets, without any the deleted secrets of the vault
+// are listed to select from
+func (z *AzureManager) DeleteCancel(args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := z.loadClient()
+	if err != nil {
+		return err
+	}
+	secretIDs := args
+	if len(secretIDs) == 0 {
+		if secretIDs, err = selectTrash(z, z.Profile); err != nil {
+			return err
+		}
+	}
+	if err = requireChange("restore", z.Profile, secretIDs); err != nil {
+		return err
+	}
+
+	var restored []string
+	defer func() { notify("restore", z.Profile, restored) }()
+	for _, id := range secretIDs {
+		name, err := z.name(id)
+		if err != nil {
+			return err
+		}
+		timeCtx, cancelRestore := context.WithTimeout(ctx, helpers.PushTimeout)
+		err = client.RecoverSecret(timeCtx, name)
+		cancelRestore()
+		if err != nil {
+			return err
+		}
+		restored = append(restored, id)
+		helpers.Infof("%s %s\n", id, color.GreenString("restored"))
+	}
+	return nil
+}
+
+// AzureManager ListTrash lists the deleted secrets of the vault that can still be recovered
+func (z *AzureManager) ListTrash() ([]TrashedSecret, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helpers.ListTimeout)
+	defer cancel()
+
+	client, err := z.loadClient()
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := client.DeletedSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var trashed []TrashedSecret
+	for _, d := range deleted {
+		trashed = append(trashed, TrashedSecret{ID: z.secretID(d.Name()), Profile: z.Profile, DeletionDate: d.PurgeDate()})
+	}
+	return trashed, nil
+}
+
+// AzureManager FuzzyFind
+func (z *AzureManager) FuzzyFind(ctx context.Context) ([]string, error) {
+	allIDs, err := z.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	return findWithPicks(allIDs, z.Profile)
+}
+
+// AzureManager Get
+func (z *AzureManager) Get(secretsIDList []string) ([]Secret, error) {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	client, err := z.loadClient()
+	if err != nil {
+		return []Secret{}, err
+	}
+	secretIDs := secretsIDList
+	if len(secretIDs) == 0 {
+		if secretIDs, err = z.FuzzyFind(ctx); err != nil {
+			return []Secret{}, err
+		}
+	}
+
+	var Secrets []Secret
+	defer helpers.StartWork()()
+	for i, id := range secretIDs {
+		if err = interrupted("pull", secretIDs[:i], secretIDs[i:]); err != nil {
+			return Secrets, err
+		}
+		name, err := z.name(id)
+		if err != nil {
+			// a name key vault can not hold is never found
+			continue
+		}
+		timeCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+		value, err := client.GetSecret(timeCtx, name, "")
+		cancelGet()
+		if err != nil {
+			if stopErr := interrupted("pull", secretIDs[:i], secretIDs[i:]); stopErr != nil {
+				return Secrets, stopErr
+			}
+			return []Secret{}, err
+		}
+		if value == nil {
+			continue
+		}
+		helpers.AddRedaction(*value)
+		Secrets = append(Secrets, Secret{
+			ID:        id,
+			Content:   *value,
+			recipient: z.AgeRecipient,
+			profile:   z.Profile,
+		})
+		helpers.Progress(helpers.ProgressEvent{Event: helpers.EventSecretPulled, Profile: z.Profile, Secret: id})
+	}
+	return Secrets, nil
+}
+
+// AzureManager ListAll
+func (z *AzureManager) ListAll() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helpers.ListTimeout)
+	defer cancel()
+
+	client, err := z.loadClient()
+	if err != nil {
+		return []string{}, err
+	}
+	names, err := client.ListSecrets(ctx)
+	if err != nil {
+		return []string{}, err
+	}
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		ids = append(ids, z.secretID(name))
+	}
+	return ids, nil
+}
+
+// AzureManager Rollback
+func (z *AzureManager) Rollback() error {
+	return z.RollbackSteps(1)
+}
+
+// AzureManager RollbackSteps sets the value of the version from the given number of enabled versions back as a new version
+func (z *AzureManager) RollbackSteps(steps int) error {
+	if steps < 1 {
+		return Errorf(KindValidation, "steps must be at least 1")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := z.loadClient()
+	if err != nil {
+		return err
+	}
+	sID, err := z.FuzzyFind(ctx)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("rollback", z.Profile, sID); err != nil {
+		return err
+	}
+
+	var rolledBack []string
+	defer func() { notify("rollback", z.Profile, rolledBack) }()
+	for _, id := range sID {
+		if err = z.rollbackSecret(ctx, client, id, steps); err != nil {
+			return err
+		}
+		rolledBack = append(rolledBack, id)
+	}
+	return nil
+}
+
+func (z *AzureManager) rollbackSecret(ctx context.Context, client *azure.Client, id string, steps int) error {
+	name, err := z.name(id)
+	if err != nil {
+		return err
+	}
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancel()
+	versions, err := client.Versions(timeCtx, name)
+	if err != nil {
+		return err
+	}
+	if len(versions) <= steps {
+		older := len(versions) - 1
+		if older < 0 {
+			older = 0
+		}
+		return Errorf(KindValidation, "%s has %d enabled older version(s), can not roll back %d", id, older, steps)
+	}
+	return z.restore(timeCtx, client, id, name, versions[steps].Version())
+}
+
+// restore sets the value of the target version as a new version of the secret
+func (z *AzureManager) restore(ctx context.Context, client *azure.Client, id string, name string, target string) error {
+	value, err := client.GetSecret(ctx, name, target)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return Errorf(KindNotFound, "version %s of %s was not found", target, id)
+	}
+	if err = client.SetSecret(ctx, name, *value); err != nil {
+		return err
+	}
+	helpers.Infof("%s %s to version %s\n", id, color.YellowString("rolled back"), target)
+	return nil
+}
+
+// AzureManager Set pushes the local secrets as new versions
+func (z *AzureManager) Set(secretsPath string, createPrompt bool) error {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	client, err := z.loadClient()
+	if err != nil {
+		return err
+	}
+	sID, err := helpers.GetSecretNames(secretsPath)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("push", z.Profile, sID); err != nil {
+		return err
+	}
+
+	var pushed []string
+	defer func() {
+		trackRecent(z.Profile, pushed...)
+		notify("push", z.Profile, pushed)
+	}()
+	defer helpers.StartWork()()
+	for i, id := range sID {
+		if err = interrupted("push", sID[:i], sID[i:]); err != nil {
+			return err
+		}
+		secretUpdate, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", secretsPath, id))
+		if err != nil {
+			return err
+		}
+		value := string(secretUpdate)
+		helpers.AddRedaction(value)
+		changed, err := z.push(ctx, client, id, value, createPrompt)
+		progressPush(z.Profile, id, err, changed)
+		if err != nil {
+			if stopErr := interrupted("push", sID[:i], sID[i:]); stopErr != nil {
+				return stopErr
+			}
+			return err
+		}
+		if changed {
+			pushed = append(pushed, id)
+		}
+	}
+	return nil
+}
+
+// push sets the value as a new version of the secret, it reports whether a version was written
+func (z *AzureManager) push(ctx context.Context, client *azure.Client, id string, value string, createPrompt bool) (bool, error) {
+	name, err := z.name(id)
+	if err != nil {
+		return false, err
+	}
+	getCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+	current, err := client.GetSecret(getCtx, name, "")
+	cancelGet()
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case current != nil && *current == value:
+		helpers.Infof("%s %s\n", id, color.CyanString("skipped"))
+		return false, nil
+	case current == nil && !createPrompt && !helpers.PromptYesNo(fmt.Sprintf("%s was not found, would you like to create this secret?", id)):
+		helpers.Infof("creation of %s %s\n", id, color.CyanString("skipped"))
+		return false, nil
+	}
+
+	pushCtx, cancelPush := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancelPush()
+	if err = client.SetSecret(pushCtx, name, value); err != nil {
+		return false, err
+	}
+	if current == nil {
+		helpers.Infof("%s %s\n", id, color.MagentaString("created"))
+	} else {
+		helpers.Infof("%s %s\n", id, color.YellowString("updated"))
+	}
+	return true, nil
+}
@@ -3,3 +3,39 @@ package secretsmanager
 func (a *AWSManager) ProfileName() string {
 	return a.Profile
 }
+
+func (e *EnvManager) ProfileName() string {
+	return e.Profile
+}
+
+func (c *ChainManager) ProfileName() string {
+	return c.Profile
+}
+
+func (v *VaultManager) ProfileName() string {
+	return v.Profile
+}
+
+func (z *AzureManager) ProfileName() string {
+	return z.Profile
+}
+
+func (k *K8sManager) ProfileName() string {
+	return k.Profile
+}
+
+func (o *OnePasswordManager) ProfileName() string {
+	return o.Profile
+}
+
+func (d *DopplerManager) ProfileName() string {
+	return d.Profile
+}
+
+func (p *PluginManager) ProfileName() string {
+	return p.Profile
+}
+
+func (b *BitwardenManager) ProfileName() string {
+	return b.Profile
+}
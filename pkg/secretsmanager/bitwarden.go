@@ -0,0 +1,297 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/internal/bitwarden"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// BitwardenManager stores secrets in Bitwarden Secrets Manager through the bws cli, secret IDs are the keys of
+// the secrets. Without a project every secret the machine account can read is listed, creating needs one.
+type BitwardenManager struct {
+	Profile      string
+	Project      string `hcl:"project,optional"`
+	AccessToken  string `hcl:"access_token,optional"`
+	ServerURL    string `hcl:"server_url,optional"`
+	BWS          string `hcl:"bws,optional"`
+	AgeRecipient string `hcl:"age_recipient,optional"`
+	client       *bitwarden.BWS
+}
+
+// loadClient returns the bws client, the access token defaults to BWS_ACCESS_TOKEN and the project to
+// BWS_PROJECT_ID
+func (b *BitwardenManager) loadClient() (*bitwarden.BWS, error) {
+	if b.client != nil {
+		return b.client, nil
+	}
+	helpers.Verbosef(helpers.VerbosityDebug, "loading bitwarden client for profile %s\n", b.Profile)
+	token := firstNonEmpty(b.AccessToken, os.Getenv("BWS_ACCESS_TOKEN"))
+	if token == "" {
+		return nil, Errorf(KindAuth, "bitwarden manager %s needs an access_token or BWS_ACCESS_TOKEN", b.Profile)
+	}
+	helpers.AddRedaction(token)
+	b.Project = firstNonEmpty(b.Project, os.Getenv("BWS_PROJECT_ID"))
+	b.client = &bitwarden.BWS{Path: b.BWS, Token: token, ServerURL: firstNonEmpty(b.ServerURL, os.Getenv("BWS_SERVER_URL"))}
+	return b.client, nil
+}
+
+// secrets lists the secrets by key, a key used by more than one secret is an error once it is looked up
+func (b *BitwardenManager) secrets(ctx context.Context) (map[string][]bitwarden.Secret, error) {
+	client, err := b.loadClient()
+	if err != nil {
+		return nil, err
+	}
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.ListTimeout)
+	defer cancel()
+	list, err := client.List(timeCtx, b.Project)
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string][]bitwarden.Secret{}
+	for _, s := range list {
+		byKey[s.Key] = append(byKey[s.Key], s)
+	}
+	return byKey, nil
+}
+
+// lookup returns the secret with the key, ok is false when there is none
+func (b *BitwardenManager) lookup(byKey map[string][]bitwarden.Secret, key string) (s bitwarden.Secret, ok bool, err error) {
+	found := byKey[key]
+	switch len(found) {
+	case 0:
+		return s, false, nil
+	case 1:
+		return found[0], true, nil
+	}
+	return s, false, Errorf(KindConflict, "bitwarden manager %s has %d secrets with the key %s, set project to pick one", b.Profile, len(found), key)
+}
+
+// BitwardenManager Create
+func (b *BitwardenManager) Create(args []string, secretsPath string, useEditor bool) error {
+	return createLocalSecret(args, secretsPath, useEditor)
+}
+
+// BitwardenManager Delete deletes the selected secrets, Bitwarden deletes them right away so the number of days
+// is not used
+func (b *BitwardenManager) Delete(scheduleInDays int64) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	byKey, err := b.secrets(ctx)
+	if err != nil {
+		return err
+	}
+	sID, err := findWithPicks(bitwardenKeys(byKey), b.Profile)
+	if err != nil {
+		return err
+	}
+	if len(sID) == 0 {
+		return nil
+	}
+	if err = requireChange("delete", b.Profile, sID); err != nil {
+		return err
+	}
+	var ids []string
+	for _, key := range sID {
+		s, _, err := b.lookup(byKey, key)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, s.ID)
+	}
+
+	timeCtx, cancelDelete := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancelDelete()
+	if err = b.client.Delete(timeCtx, ids...); err != nil {
+		return err
+	}
+	notify("delete", b.Profile, sID)
+	for _, id := range sID {
+		helpers.Infof("%s %s\n", id, color.RedString("deleted"))
+	}
+	return nil
+}
+
+// BitwardenManager DeleteCancel
+func (b *BitwardenManager) DeleteCancel(args []string) error {
+	return Errorf(KindUnsupported, "bitwarden manager %s does not support scheduled deletions", b.Profile)
+}
+
+// BitwardenManager FuzzyFind
+func (b *BitwardenManager) FuzzyFind(ctx context.Context) ([]string, error) {
+	allIDs, err := b.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	return findWithPicks(allIDs, b.Profile)
+}
+
+// BitwardenManager Get pulls the secrets from one listing, bws returns the values with the list
+func (b *BitwardenManager) Get(secretsIDList []string) ([]Secret, error) {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	defer helpers.StartWork()()
+	byKey, err := b.secrets(ctx)
+	if err != nil {
+		if stopErr := interrupted("pull", nil, secretsIDList); stopErr != nil {
+			return []Secret{}, stopErr
+		}
+		return []Secret{}, err
+	}
+	secretIDs := secretsIDList
+	if len(secretIDs) == 0 {
+		if secretIDs, err = findWithPicks(bitwardenKeys(byKey), b.Profile); err != nil {
+			return []Secret{}, err
+		}
+	}
+
+	var Secrets []Secret
+	for _, id := range secretIDs {
+		s, ok, err := b.lookup(byKey, id)
+		if err != nil {
+			return Secrets, err
+		}
+		if !ok {
+			continue
+		}
+		helpers.AddRedaction(s.Value)
+		Secrets = append(Secrets, Secret{
+			ID:        id,
+			Content:   s.Value,
+			recipient: b.AgeRecipient,
+			profile:   b.Profile,
+		})
+		helpers.Progress(helpers.ProgressEvent{Event: helpers.EventSecretPulled, Profile: b.Profile, Secret: id})
+	}
+	return Secrets, nil
+}
+
+// BitwardenManager ListAll
+func (b *BitwardenManager) ListAll() ([]string, error) {
+	byKey, err := b.secrets(context.Background())
+	if err != nil {
+		return []string{}, err
+	}
+	return bitwardenKeys(byKey), nil
+}
+
+// BitwardenManager ListInfo lists the secrets with the time they were last changed
+func (b *BitwardenManager) ListInfo() ([]SecretInfo, error) {
+	byKey, err := b.secrets(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]SecretInfo, 0, len(byKey))
+	for _, key := range bitwardenKeys(byKey) {
+		infos = append(infos, SecretInfo{ID: key, Modified: byKey[key][0].RevisionDate})
+	}
+	return infos, nil
+}
+
+// BitwardenManager Rollback
+func (b *BitwardenManager) Rollback() error {
+	return Errorf(KindUnsupported, "bitwarden manager %s does not keep secret versions", b.Profile)
+}
+
+// BitwardenManager Set pushes the local secrets, new secrets are created in the project
+func (b *BitwardenManager) Set(secretsPath string, createPrompt bool) error {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	sID, err := helpers.GetSecretNames(secretsPath)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("push", b.Profile, sID); err != nil {
+		return err
+	}
+	if len(sID) == 0 {
+		return nil
+	}
+	byKey, err := b.secrets(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pushed []string
+	defer func() {
+		trackRecent(b.Profile, pushed...)
+		notify("push", b.Profile, pushed)
+	}()
+	defer helpers.StartWork()()
+	for i, id := range sID {
+		if err = interrupted("push", sID[:i], sID[i:]); err != nil {
+			return err
+		}
+		secretUpdate, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", secretsPath, id))
+		if err != nil {
+			return err
+		}
+		value := string(secretUpdate)
+		helpers.AddRedaction(value)
+		changed, err := b.push(ctx, byKey, id, value, createPrompt)
+		progressPush(b.Profile, id, err, changed)
+		if err != nil {
+			if stopErr := interrupted("push", sID[:i], sID[i:]); stopErr != nil {
+				return stopErr
+			}
+			return err
+		}
+		if changed {
+			pushed = append(pushed, id)
+		}
+	}
+	return nil
+}
+
+// push edits or creates the secret, it reports whether the value was written
+func (b *BitwardenManager) push(ctx context.Context, byKey map[string][]bitwarden.Secret, id string, value string, createPrompt bool) (bool, error) {
+	current, exists, err := b.lookup(byKey, id)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case exists && current.Value == value:
+		helpers.Infof("%s %s\n", id, color.CyanString("skipped"))
+		return false, nil
+	case !exists && b.Project == "":
+		return false, Errorf(KindValidation, "bitwarden manager %s needs a project to create %s", b.Profile, id)
+	case !exists && !createPrompt && !helpers.PromptYesNo(fmt.Sprintf("%s was not found, would you like to create this secret?", id)):
+		helpers.Infof("creation of %s %s\n", id, color.CyanString("skipped"))
+		return false, nil
+	}
+
+	pushCtx, cancelPush := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancelPush()
+	if exists {
+		if err = b.client.Edit(pushCtx, current.ID, value); err != nil {
+			return false, err
+		}
+		helpers.Infof("%s %s\n", id, color.YellowString("updated"))
+		return true, nil
+	}
+	created, err := b.client.Create(pushCtx, id, value, b.Project)
+	if err != nil {
+		return false, err
+	}
+	byKey[id] = []bitwarden.Secret{created}
+	helpers.Infof("%s %s\n", id, color.MagentaString("created"))
+	return true, nil
+}
+
+// bitwardenKeys returns the sorted keys of the listed secrets
+func bitwardenKeys(byKey map[string][]bitwarden.Secret) []string {
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,43 @@
+package secretsmanager
+
+import (
+	"sync"
+
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// fetchCache remembers the secrets fetched by a manager during this run so the same secret is never
+// requested twice, i.e. when checking every profile for duplicates and then narrowing missing secrets
+type fetchCache struct {
+	mu sync.Mutex
+	// values maps a secret ID to its value, nil marks a secret known to be missing
+	values map[string]*Secret
+}
+
+// get returns the cached value of the secret, cached is false when the secret has not been fetched yet
+func (c *fetchCache) get(secretID string) (value *Secret, cached bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, cached = c.values[secretID]
+	if cached {
+		helpers.Verbosef(helpers.VerbosityDebug, "using cached %s\n", secretID)
+	}
+	return value, cached
+}
+
+// put caches the value of the secret, nil records the secret as missing
+func (c *fetchCache) put(secretID string, value *Secret) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil {
+		c.values = map[string]*Secret{}
+	}
+	c.values[secretID] = value
+}
+
+// reset forgets every cached secret, called after the manager changed secrets
+func (c *fetchCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = nil
+}
@@ -0,0 +1,145 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ChainManager tries a list of other profiles in order, a secret is pulled from the first
+// manager that contains it while changes are always sent to the first manager in the chain
+type ChainManager struct {
+	Profile  string
+	Fallback []string `hcl:"fallback"`
+	managers []Manager
+}
+
+// resolve looks up the fallback profiles from the list of configured managers
+func (c *ChainManager) resolve(managers []Manager) error {
+	if len(c.Fallback) == 0 {
+		return Errorf(KindValidation, "chain manager %s needs at least one fallback profile", c.Profile)
+	}
+	c.managers = nil
+	for _, profile := range c.Fallback {
+		var found Manager
+		for _, m := range managers {
+			if m.ProfileName() == profile {
+				found = m
+				break
+			}
+		}
+		if found == nil {
+			return Errorf(KindValidation, "chain manager %s: unknown fallback profile `%s`", c.Profile, profile)
+		}
+		if _, ok := found.(*ChainManager); ok {
+			return Errorf(KindValidation, "chain manager %s: fallback profile `%s` can not be another chain", c.Profile, profile)
+		}
+		c.managers = append(c.managers, found)
+	}
+	return nil
+}
+
+// ChainManager Create
+func (c *ChainManager) Create(args []string, secretsPath string, useEditor bool) error {
+	return createLocalSecret(args, secretsPath, useEditor)
+}
+
+// ChainManager Delete
+func (c *ChainManager) Delete(scheduleInDays int64) error {
+	if err := requireProtected("delete", c.Profile); err != nil {
+		return err
+	}
+	return c.managers[0].Delete(scheduleInDays)
+}
+
+// ChainManager DeleteCancel
+func (c *ChainManager) DeleteCancel(args []string) error {
+	if err := requireProtected("restore", c.Profile); err != nil {
+		return err
+	}
+	return c.managers[0].DeleteCancel(args)
+}
+
+// ChainManager FuzzyFind
+func (c *ChainManager) FuzzyFind(ctx context.Context) ([]string, error) {
+	allIDs, err := c.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	return findWithPicks(allIDs, append([]string{c.Profile}, c.Fallback...)...)
+}
+
+// ChainManager Get
+func (c *ChainManager) Get(secretsIDList []string) ([]Secret, error) {
+	var Secrets []Secret
+	var err error
+
+	secretIDs := secretsIDList
+	if len(secretIDs) == 0 {
+		secretIDs, err = c.FuzzyFind(context.Background())
+		if err != nil {
+			return []Secret{}, err
+		}
+	}
+
+	remaining := secretIDs
+	for _, m := range c.managers {
+		if len(remaining) == 0 {
+			break
+		}
+		found, err := m.Get(remaining)
+		if IsInterrupted(err) {
+			return append(Secrets, found...), fmt.Errorf("%s: %w", m.ProfileName(), err)
+		}
+		if err != nil {
+			return []Secret{}, fmt.Errorf("%s: %w", m.ProfileName(), err)
+		}
+		Secrets = append(Secrets, found...)
+		remaining = MissingRefs(remaining, found)
+	}
+
+	order := map[string]int{}
+	for i, id := range secretIDs {
+		order[id] = i
+	}
+	sort.SliceStable(Secrets, func(i, j int) bool {
+		return order[Secrets[i].ID] < order[Secrets[j].ID]
+	})
+	return Secrets, nil
+}
+
+// ChainManager ListAll returns the combined list of secrets from every manager in the chain
+func (c *ChainManager) ListAll() ([]string, error) {
+	seen := map[string]bool{}
+	var list []string
+	for _, m := range c.managers {
+		ids, err := m.ListAll()
+		if err != nil {
+			return []string{}, fmt.Errorf("%s: %w", m.ProfileName(), err)
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				list = append(list, id)
+			}
+		}
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+// ChainManager Rollback
+func (c *ChainManager) Rollback() error {
+	if err := requireProtected("rollback", c.Profile); err != nil {
+		return err
+	}
+	return c.managers[0].Rollback()
+}
+
+// ChainManager Set
+func (c *ChainManager) Set(secretsPath string, createPrompt bool) error {
+	if err := requireProtected("push", c.Profile); err != nil {
+		return err
+	}
+	return c.managers[0].Set(secretsPath, createPrompt)
+}
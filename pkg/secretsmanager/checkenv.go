@@ -0,0 +1,109 @@
+package secretsmanager
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// secretKeyPattern matches variable names that usually hold a secret
+var secretKeyPattern = regexp.MustCompile(`(?i)(SECRET|PASSWORD|PASSWD|TOKEN|API_?KEY|PRIVATE|CREDENTIAL|AUTH|DSN|_KEY$)`)
+
+// EnvProblem is a key of an env file that check-env warns about
+type EnvProblem struct {
+	Line    int
+	Key     string
+	Problem string
+}
+
+// CheckEnvFile compares an env file with the secrets of the manager. Keys named like secrets that match no
+// managed secret are reported as possibly hand added plain text, keys matching a managed secret are reported
+// when their value differs from the one jaws would pull now. A key matches a secret when it equals EnvKey of
+// the secret ID, or of the ID without the prefix when one is given, DB_PASSWORD matches app/db/password with the
+// prefix app/. A key matching more than one secret is reported as ambiguous and none of them is pulled.
+func CheckEnvFile(m Manager, path string, prefix string) ([]EnvProblem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ids, err := m.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	index := map[string][]string{}
+	for _, id := range ids {
+		index[EnvKey(id)] = append(index[EnvKey(id)], id)
+		if prefix != "" && strings.HasPrefix(id, prefix) && id != prefix {
+			key := EnvKey(strings.TrimPrefix(id, prefix))
+			index[key] = append(index[key], id)
+		}
+	}
+	for key, matches := range index {
+		index[key] = dedupe(matches)
+		sort.Strings(index[key])
+	}
+
+	type entry struct {
+		line  int
+		key   string
+		value string
+	}
+	var entries []entry
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if key, value, ok := helpers.DotEnvValue(scanner.Text()); ok {
+			entries = append(entries, entry{line: lineNum, key: key, value: value})
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var problems []EnvProblem
+	var pull []string
+	for _, e := range entries {
+		matches := index[e.key]
+		switch {
+		case len(matches) > 1:
+			problems = append(problems, EnvProblem{Line: e.line, Key: e.key, Problem: fmt.Sprintf("matches more than one secret: %s", strings.Join(matches, ", "))})
+		case len(matches) == 1:
+			pull = append(pull, matches[0])
+		case e.value != "" && secretKeyPattern.MatchString(e.key):
+			problems = append(problems, EnvProblem{Line: e.line, Key: e.key, Problem: fmt.Sprintf("looks like a secret but matches no secret in profile %s", m.ProfileName())})
+		}
+	}
+	if len(pull) != 0 {
+		Secrets, err := m.Get(dedupe(pull))
+		if err != nil {
+			return nil, err
+		}
+		values := map[string]string{}
+		for _, s := range Secrets {
+			if !s.IsBinary() {
+				values[s.ID] = s.Content
+			}
+		}
+		for _, e := range entries {
+			matches := index[e.key]
+			if len(matches) != 1 {
+				continue
+			}
+			if strings.TrimSuffix(values[matches[0]], "\n") != e.value {
+				problems = append(problems, EnvProblem{Line: e.line, Key: e.key, Problem: fmt.Sprintf("differs from %s", matches[0])})
+			}
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+	return problems, nil
+}
@@ -0,0 +1,82 @@
+package secretsmanager_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jacbart/jaws/pkg/secretsmanager"
+	"github.com/jacbart/jaws/pkg/secretsmanager/fake"
+)
+
+func TestCheckEnvFile(t *testing.T) {
+	m := fake.New("test", map[string]string{
+		"prod/app/db/password": "pw",
+		"prod/app/api-key":     "key",
+		"prod/other/token":     "t1",
+		"prod/worker/token":    "t2",
+		"app/db-url":           "url1",
+		"app/db/url":           "url2",
+	})
+	env := `DB_PASSWORD=pw
+API_KEY=stale
+TOKEN=t1
+APP_DB_URL=url1
+STRIPE_SECRET_KEY=sk
+DEBUG=true
+`
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(env), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   []secretsmanager.EnvProblem
+	}{
+		{
+			name: "full ids only",
+			want: []secretsmanager.EnvProblem{
+				{Line: 1, Key: "DB_PASSWORD", Problem: "looks like a secret but matches no secret in profile test"},
+				{Line: 2, Key: "API_KEY", Problem: "looks like a secret but matches no secret in profile test"},
+				{Line: 3, Key: "TOKEN", Problem: "looks like a secret but matches no secret in profile test"},
+				{Line: 4, Key: "APP_DB_URL", Problem: "matches more than one secret: app/db-url, app/db/url"},
+				{Line: 5, Key: "STRIPE_SECRET_KEY", Problem: "looks like a secret but matches no secret in profile test"},
+			},
+		},
+		{
+			name:   "prefix",
+			prefix: "prod/app/",
+			want: []secretsmanager.EnvProblem{
+				{Line: 2, Key: "API_KEY", Problem: "differs from prod/app/api-key"},
+				{Line: 3, Key: "TOKEN", Problem: "looks like a secret but matches no secret in profile test"},
+				{Line: 4, Key: "APP_DB_URL", Problem: "matches more than one secret: app/db-url, app/db/url"},
+				{Line: 5, Key: "STRIPE_SECRET_KEY", Problem: "looks like a secret but matches no secret in profile test"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secretsmanager.CheckEnvFile(m, path, tt.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckEnvFile =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+	// only the unambiguous matches are pulled
+	for _, c := range m.Calls() {
+		if c.Method != "Get" {
+			continue
+		}
+		for _, id := range c.Args {
+			if id == "prod/other/token" || id == "prod/worker/token" || id == "app/db-url" || id == "app/db/url" {
+				t.Errorf("CheckEnvFile pulled %s", id)
+			}
+		}
+	}
+}
@@ -1,17 +1,58 @@
 package secretsmanager
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/fatih/color"
+	"github.com/jacbart/jaws/utils/helpers"
 )
 
-// Clean
-func Clean(secretsPath string) error {
+// Clean removes the secrets path then offers to remove any other tracked output, categories limits
+// which tracked outputs are considered and assumeYes skips the confirmation prompt
+func Clean(secretsPath string, categories []string, assumeYes bool) error {
 	err := os.RemoveAll(secretsPath)
 	if err != nil {
 		return nil
 	}
-	color.Red("folder '%s' deleted\n", secretsPath)
-	return nil
+	helpers.Infof("%s\n", color.RedString("folder '%s' deleted", secretsPath))
+
+	outputs, err := LoadOutputs()
+	if err != nil {
+		return err
+	}
+	outputs.Prune()
+
+	selected := Outputs{}
+	total := 0
+	for category, paths := range outputs {
+		if len(categories) != 0 && !helpers.Contains(categories, category) {
+			continue
+		}
+		selected[category] = paths
+		total += len(paths)
+	}
+	if total == 0 {
+		return outputs.save()
+	}
+
+	for category, paths := range selected {
+		fmt.Printf("%s:\n", color.MagentaString(category))
+		for _, p := range paths {
+			fmt.Printf("  %s\n", p)
+		}
+	}
+	if !assumeYes && !helpers.PromptYesNo(fmt.Sprintf("remove %d tracked file(s)?", total)) {
+		return outputs.save()
+	}
+	for category, paths := range selected {
+		for _, p := range paths {
+			if err = os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			helpers.Infof("%s\n", color.RedString("%s deleted", p))
+		}
+		delete(outputs, category)
+	}
+	return outputs.save()
 }
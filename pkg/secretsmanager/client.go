@@ -3,33 +3,49 @@ package secretsmanager
 import (
 	"context"
 	"fmt"
+	"sync"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/jacbart/jaws/utils/helpers"
 )
 
-// LoadAWSClient
+// awsClient holds the client of a manager once it is loaded
+type awsClient struct {
+	mu     sync.Mutex
+	client *secretsmanager.Client
+}
+
+// LoadAWSClient returns the client of the manager, it is loaded once and reused so the credentials are only
+// resolved again when they expire, i.e. by a jaws agent serving many pulls
 func LoadAWSClient(a *AWSManager, ctx context.Context) (*secretsmanager.Client, error) {
-	var client *secretsmanager.Client
+	a.client.mu.Lock()
+	defer a.client.mu.Unlock()
+	if a.client.client != nil {
+		return a.client.client, nil
+	}
+	helpers.Verbosef(helpers.VerbosityDebug, "loading aws client for profile %s\n", a.Profile)
+	cfg, err := loadAWSConfig(a, ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.client.client = secretsmanager.NewFromConfig(cfg)
+	return a.client.client, nil
+}
 
+// loadAWSConfig loads the aws config using the static credentials of the manager if set
+func loadAWSConfig(a *AWSManager, ctx context.Context) (aws.Config, error) {
 	if a.AccessID != "" {
-		cfg, err := config.LoadDefaultConfig(ctx,
+		helpers.AddRedaction(a.SecretKey)
+		return config.LoadDefaultConfig(ctx,
 			config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(a.AccessID, a.SecretKey, "")),
 		)
-		if err != nil {
-			return nil, err
-		}
-
-		client = secretsmanager.NewFromConfig(cfg)
-		return client, nil
 	}
 	cfg, err := config.LoadDefaultConfig(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("unable to load AWS config, %v", err)
+		return cfg, fmt.Errorf("unable to load AWS config, %v", err)
 	}
-
-	client = secretsmanager.NewFromConfig(cfg)
-
-	return client, nil
+	return cfg, nil
 }
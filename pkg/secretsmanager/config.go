@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"text/template"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/jacbart/jaws/utils/helpers"
@@ -25,28 +27,98 @@ type Manager interface {
 	Set(string, bool) error
 }
 
+// Describer is implemented by managers that can list secrets along with their metadata
+type Describer interface {
+	ListInfo() ([]SecretInfo, error)
+}
+
+// VersionRollbacker is implemented by managers that can roll back more than one version
+type VersionRollbacker interface {
+	RollbackSteps(int) error
+}
+
+// VersionDestroyer is implemented by managers that can retire old versions of a secret
+type VersionDestroyer interface {
+	DestroyVersions([]string, time.Time, bool) (int, error)
+}
+
+// Historian is implemented by managers that can list the versions of a secret, read an older one and
+// restore it as the newest version
+type Historian interface {
+	History(string) ([]SecretVersion, error)
+	GetVersion(string, string) (Secret, error)
+	RestoreVersion(string, string) error
+}
+
+// Replicator is implemented by managers that can replicate secrets to other regions
+type Replicator interface {
+	ReplicateSecrets([]string, []string, bool) (int, error)
+}
+
+// Trasher is implemented by managers that can list their secrets pending deletion
+type Trasher interface {
+	ListTrash() ([]TrashedSecret, error)
+}
+
+// Querier is implemented by managers that can filter a listing on the provider side
+type Querier interface {
+	ListQuery(Query) ([]SecretInfo, error)
+}
+
+// Tagger is implemented by managers that can tag or label secrets
+type Tagger interface {
+	TagSecrets([]string, map[string]string) error
+	UntagSecrets([]string, []string) error
+}
+
+// DescriptionSetter is implemented by managers keeping a description next to each secret
+type DescriptionSetter interface {
+	SetDescription(string, string) error
+}
+
 type Config struct {
-	General  GeneralHCL   `hcl:"general,block"`
-	Managers []managerHCL `hcl:"manager,block"`
+	General    GeneralHCL     `hcl:"general,block"`
+	Naming     *NamingHCL     `hcl:"naming,block"`
+	Notify     []NotifyHCL    `hcl:"notify,block"`
+	Ticket     *TicketHCL     `hcl:"ticket,block"`
+	Groups     []GroupHCL     `hcl:"group,block"`
+	Workspaces []WorkspaceHCL `hcl:"workspace,block"`
+	Aliases    []AliasHCL     `hcl:"alias,block"`
+	Tasks      []TaskHCL      `hcl:"task,block"`
+	Managers   []managerHCL   `hcl:"manager,block"`
 }
 
 type GeneralHCL struct {
-	DefaultProfile string `hcl:"default_profile,optional"`
-	Editor         string `hcl:"editor,optional"`
-	SecretsPath    string `hcl:"secrets_path,optional"`
+	DefaultProfile     string       `hcl:"default_profile,optional"`
+	DefaultPushProfile string       `hcl:"default_push_profile,optional"`
+	Editor             string       `hcl:"editor,optional"`
+	SecretsPath        string       `hcl:"secrets_path,optional"`
+	ProfileOrder       []string     `hcl:"profile_order,optional"`
+	SuggestDist        int          `hcl:"suggest_distance,optional"`
+	KeepVersions       *int         `hcl:"keep_versions,optional"`
+	Timeouts           *TimeoutsHCL `hcl:"timeouts,block"`
 }
 
 type managerHCL struct {
-	Platform string   `hcl:"platform,label"`
-	Profile  string   `hcl:"profile,label"`
-	Auth     hcl.Body `hcl:",remain"`
+	Platform  string   `hcl:"platform,label"`
+	Profile   string   `hcl:"profile,label"`
+	Protected bool     `hcl:"protected,optional"`
+	Auth      hcl.Body `hcl:",remain"`
 }
 
 type AWSManager struct {
-	Profile   string
-	AccessID  string `hcl:"access_id,optional"`
-	SecretKey string `hcl:"secret_key,optional"`
-	Region    string `hcl:"region,optional"`
+	Profile        string
+	AccessID       string            `hcl:"access_id,optional"`
+	SecretKey      string            `hcl:"secret_key,optional"`
+	Region         string            `hcl:"region,optional"`
+	AgeRecipient   string            `hcl:"age_recipient,optional"`
+	ChangedBy      string            `hcl:"changed_by,optional"`
+	Tags           map[string]string `hcl:"tags,optional"`
+	KMSKeyID       string            `hcl:"kms_key_id,optional"`
+	ReplicaRegions []string          `hcl:"replica_regions,optional"`
+	callerARN      string
+	cache          fetchCache
+	client         awsClient
 }
 
 //go:embed config.tmpl
@@ -57,7 +129,7 @@ func CreateConfig() error {
 		General: GeneralHCL{
 			DefaultProfile: "default",
 			Editor:         os.Getenv("EDITOR"),
-			SecretsPath:    fmt.Sprintf("%s/.jaws/secrets", os.Getenv("HOME")),
+			SecretsPath:    filepath.Join(helpers.StateDir(), "secrets"),
 		},
 		Managers: []managerHCL{
 			{
@@ -70,7 +142,7 @@ func CreateConfig() error {
 
 	tmpl, err := template.New("jaws.conf").Funcs(helpers.TemplateFuncs).Parse(configTmpl)
 	if err != nil {
-    return fmt.Errorf("tmpl parse phase: %w", err)
+		return fmt.Errorf("tmpl parse phase: %w", err)
 	}
 	err = tmpl.Execute(os.Stdout, c)
 	if err != nil {
@@ -11,6 +11,11 @@ import (
 
 // AWSManager Create
 func (a *AWSManager) Create(args []string, secretsPath string, useEditor bool) error {
+	return createLocalSecret(args, secretsPath, useEditor)
+}
+
+// createLocalSecret creates the folder path and an empty secret file, optionally opening it in an editor
+func createLocalSecret(args []string, secretsPath string, useEditor bool) error {
 	pattern := strings.Split(args[0], "/")
 	filePath := fmt.Sprintf("%s/%s", secretsPath, args[0])
 	dir := fmt.Sprintf("%s/%s", secretsPath, strings.Join(pattern[:len(pattern)-1], "/"))
@@ -23,7 +28,7 @@ func (a *AWSManager) Create(args []string, secretsPath string, useEditor bool) e
 		return err
 	}
 	defer f.Close()
-	color.Red("%s/%s created locally\n", secretsPath, args[0])
+	helpers.Infof("%s\n", color.RedString("%s/%s created locally", secretsPath, args[0]))
 	if useEditor {
 		if err = helpers.OpenEditor(args, secretsPath); err != nil {
 			return err
@@ -2,13 +2,18 @@ package secretsmanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/fatih/color"
 	"github.com/jacbart/jaws/internal/aws"
+	"github.com/ktr0731/go-fuzzyfinder"
 )
 
 // AWSManager Delete
 func (a *AWSManager) Delete(scheduleInDays int64) error {
+	defer a.cache.reset()
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -21,18 +26,28 @@ func (a *AWSManager) Delete(scheduleInDays int64) error {
 	if err != nil {
 		return fmt.Errorf("error while iterating and printing secret names: %v", err)
 	}
+	if err = requireChange("delete", a.Profile, sID); err != nil {
+		return err
+	}
 
-	l := len(sID) - 1
-	for i := 0; i < l; i++ {
-		if err = aws.ScheduleDeletion(ctx, client, sID[i], scheduleInDays); err != nil {
+	var deleted []string
+	defer func() { notify("delete", a.Profile, deleted) }()
+	for _, id := range sID {
+		if err = aws.ScheduleDeletion(ctx, client, id, scheduleInDays); err != nil {
 			return err
 		}
+		deleted = append(deleted, id)
+		if err = TrackDeletions(a.Profile, id); err != nil {
+			color.Yellow("%s was scheduled for deletion but could not be tracked for delete cancel: %v", id, err)
+		}
 	}
 	return nil
 }
 
-// AWSManager DeleteCancel
+// AWSManager DeleteCancel restores the given secrets, without any it lists the secrets jaws scheduled
+// for deletion that can still be recovered to select from
 func (a *AWSManager) DeleteCancel(args []string) error {
+	defer a.cache.reset()
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -41,8 +56,85 @@ func (a *AWSManager) DeleteCancel(args []string) error {
 		return err
 	}
 
-	if err = aws.CancelDeletion(ctx, client, args[0]); err != nil {
+	secretIDs := args
+	if len(secretIDs) == 0 {
+		if secretIDs, err = selectTrash(a, a.Profile); err != nil {
+			return err
+		}
+	}
+	if err = requireChange("restore", a.Profile, secretIDs); err != nil {
 		return err
 	}
+	var restored []string
+	defer func() { notify("restore", a.Profile, restored) }()
+	for _, id := range secretIDs {
+		if err = aws.CancelDeletion(ctx, client, id); err != nil {
+			return err
+		}
+		restored = append(restored, id)
+		if err = UntrackDeletions(a.Profile, id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// AWSManager ListTrash lists the secrets jaws scheduled for deletion that can still be recovered,
+// secrets restored or deleted since are no longer tracked
+func (a *AWSManager) ListTrash() ([]TrashedSecret, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := LoadAWSClient(a, ctx)
+	if err != nil {
+		return nil, err
+	}
+	d, err := LoadDeletions()
+	if err != nil {
+		return nil, err
+	}
+
+	var rnfErr *types.ResourceNotFoundException
+	var trashed []TrashedSecret
+	var gone []string
+	for _, id := range d[a.Profile] {
+		deletionDate, err := aws.DeletionDate(ctx, client, id)
+		if err != nil && !errors.As(err, &rnfErr) {
+			return nil, err
+		}
+		if deletionDate == nil {
+			gone = append(gone, id)
+			continue
+		}
+		trashed = append(trashed, TrashedSecret{ID: id, Profile: a.Profile, DeletionDate: *deletionDate})
+	}
+	if len(gone) != 0 {
+		if err = UntrackDeletions(a.Profile, gone...); err != nil {
+			return nil, err
+		}
+	}
+	return trashed, nil
+}
+
+// selectTrash opens a fuzzy finder with the secrets still scheduled for deletion along with the time left to recover them
+func selectTrash(t Trasher, profile string) ([]string, error) {
+	trashed, err := t.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+	if len(trashed) == 0 {
+		return nil, Errorf(KindNotFound, "no secrets scheduled for deletion found in profile %s, pass the secret name to cancel its deletion", profile)
+	}
+
+	idxs, err := fuzzyfinder.FindMulti(trashed, func(i int) string {
+		return fmt.Sprintf("%s (recoverable for %s)", trashed[i].ID, trashed[i].Remaining())
+	}, fuzzyfinder.WithHeader("select secrets to restore"))
+	if err != nil && !errors.Is(err, fuzzyfinder.ErrAbort) {
+		return nil, err
+	}
+	var selected []string
+	for _, idx := range idxs {
+		selected = append(selected, trashed[idx].ID)
+	}
+	return selected, nil
+}
@@ -0,0 +1,60 @@
+package secretsmanager
+
+import (
+	"sort"
+
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// Deletions maps a profile to the secrets jaws scheduled for deletion with it, the providers do not
+// always list secrets pending deletion so jaws keeps track of them for delete cancel
+type Deletions map[string][]string
+
+// deletionsFile returns the path of the state file tracking scheduled deletions
+func deletionsFile() string {
+	return helpers.StatePath("deletions.json")
+}
+
+// LoadDeletions reads the tracked deletions, a missing state file returns no deletions
+func LoadDeletions() (Deletions, error) {
+	d := Deletions{}
+	if err := loadState(deletionsFile(), &d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// TrackDeletions records secrets scheduled for deletion in the profile
+func TrackDeletions(profile string, secretIDs ...string) error {
+	d, err := LoadDeletions()
+	if err != nil {
+		return err
+	}
+	for _, id := range secretIDs {
+		if !helpers.Contains(d[profile], id) {
+			d[profile] = append(d[profile], id)
+		}
+	}
+	sort.Strings(d[profile])
+	return saveState(deletionsFile(), d)
+}
+
+// UntrackDeletions forgets secrets that were restored or are gone for good
+func UntrackDeletions(profile string, secretIDs ...string) error {
+	d, err := LoadDeletions()
+	if err != nil {
+		return err
+	}
+	var kept []string
+	for _, id := range d[profile] {
+		if !helpers.Contains(secretIDs, id) {
+			kept = append(kept, id)
+		}
+	}
+	if len(kept) == 0 {
+		delete(d, profile)
+	} else {
+		d[profile] = kept
+	}
+	return saveState(deletionsFile(), d)
+}
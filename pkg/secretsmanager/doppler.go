@@ -0,0 +1,242 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/internal/doppler"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// DopplerManager stores secrets in one Doppler config, secret IDs are the Doppler secret names
+type DopplerManager struct {
+	Profile      string
+	Project      string `hcl:"project,optional"`
+	Config       string `hcl:"config,optional"`
+	Token        string `hcl:"token,optional"`
+	API          string `hcl:"api,optional"`
+	AgeRecipient string `hcl:"age_recipient,optional"`
+	client       *doppler.Client
+}
+
+// loadClient returns the Doppler client, the token defaults to DOPPLER_TOKEN and the project and config to
+// DOPPLER_PROJECT and DOPPLER_CONFIG, a service token does not need either
+func (d *DopplerManager) loadClient() (*doppler.Client, error) {
+	if d.client != nil {
+		return d.client, nil
+	}
+	helpers.Verbosef(helpers.VerbosityDebug, "loading doppler client for profile %s\n", d.Profile)
+	token := firstNonEmpty(d.Token, os.Getenv("DOPPLER_TOKEN"))
+	if token == "" {
+		return nil, Errorf(KindAuth, "doppler manager %s needs a token or DOPPLER_TOKEN", d.Profile)
+	}
+	helpers.AddRedaction(token)
+	d.client = &doppler.Client{
+		API:     d.API,
+		Token:   token,
+		Project: firstNonEmpty(d.Project, os.Getenv("DOPPLER_PROJECT")),
+		Config:  firstNonEmpty(d.Config, os.Getenv("DOPPLER_CONFIG")),
+	}
+	return d.client, nil
+}
+
+// DopplerManager Create
+func (d *DopplerManager) Create(args []string, secretsPath string, useEditor bool) error {
+	return createLocalSecret(args, secretsPath, useEditor)
+}
+
+// DopplerManager Delete deletes the selected secrets from the config, doppler keeps them in the config logs
+// so the number of days is not used
+func (d *DopplerManager) Delete(scheduleInDays int64) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := d.loadClient()
+	if err != nil {
+		return err
+	}
+	sID, err := d.FuzzyFind(ctx)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("delete", d.Profile, sID); err != nil {
+		return err
+	}
+
+	var deleted []string
+	defer func() { notify("delete", d.Profile, deleted) }()
+	for _, id := range sID {
+		timeCtx, cancelDelete := context.WithTimeout(ctx, helpers.PushTimeout)
+		err = client.Delete(timeCtx, id)
+		cancelDelete()
+		if err != nil {
+			return err
+		}
+		deleted = append(deleted, id)
+		helpers.Infof("%s %s\n", id, color.RedString("deleted"))
+	}
+	return nil
+}
+
+// DopplerManager DeleteCancel
+func (d *DopplerManager) DeleteCancel(args []string) error {
+	return Errorf(KindUnsupported, "doppler manager %s does not support scheduled deletions, roll back the config log in doppler instead", d.Profile)
+}
+
+// DopplerManager FuzzyFind
+func (d *DopplerManager) FuzzyFind(ctx context.Context) ([]string, error) {
+	allIDs, err := d.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	return findWithPicks(allIDs, d.Profile)
+}
+
+// DopplerManager Get pulls the computed values, references to other secrets are resolved
+func (d *DopplerManager) Get(secretsIDList []string) ([]Secret, error) {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	client, err := d.loadClient()
+	if err != nil {
+		return []Secret{}, err
+	}
+	secretIDs := secretsIDList
+	if len(secretIDs) == 0 {
+		if secretIDs, err = d.FuzzyFind(ctx); err != nil {
+			return []Secret{}, err
+		}
+	}
+
+	var Secrets []Secret
+	defer helpers.StartWork()()
+	for i, id := range secretIDs {
+		if err = interrupted("pull", secretIDs[:i], secretIDs[i:]); err != nil {
+			return Secrets, err
+		}
+		timeCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+		value, err := client.Get(timeCtx, id)
+		cancelGet()
+		if err != nil {
+			if stopErr := interrupted("pull", secretIDs[:i], secretIDs[i:]); stopErr != nil {
+				return Secrets, stopErr
+			}
+			return []Secret{}, err
+		}
+		if value == nil {
+			continue
+		}
+		helpers.AddRedaction(value.Computed)
+		Secrets = append(Secrets, Secret{
+			ID:        id,
+			Content:   value.Computed,
+			recipient: d.AgeRecipient,
+			profile:   d.Profile,
+		})
+		helpers.Progress(helpers.ProgressEvent{Event: helpers.EventSecretPulled, Profile: d.Profile, Secret: id})
+	}
+	return Secrets, nil
+}
+
+// DopplerManager ListAll
+func (d *DopplerManager) ListAll() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helpers.ListTimeout)
+	defer cancel()
+
+	client, err := d.loadClient()
+	if err != nil {
+		return []string{}, err
+	}
+	names, err := client.Names(ctx)
+	if err != nil {
+		return []string{}, err
+	}
+	return names, nil
+}
+
+// DopplerManager Rollback
+func (d *DopplerManager) Rollback() error {
+	return Errorf(KindUnsupported, "doppler manager %s rolls back whole configs, use the config logs in doppler", d.Profile)
+}
+
+// DopplerManager Set pushes the local secrets, a secret whose value matches the stored or the computed value
+// is skipped so references to other secrets are kept
+func (d *DopplerManager) Set(secretsPath string, createPrompt bool) error {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	client, err := d.loadClient()
+	if err != nil {
+		return err
+	}
+	sID, err := helpers.GetSecretNames(secretsPath)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("push", d.Profile, sID); err != nil {
+		return err
+	}
+
+	var pushed []string
+	defer func() {
+		trackRecent(d.Profile, pushed...)
+		notify("push", d.Profile, pushed)
+	}()
+	defer helpers.StartWork()()
+	for i, id := range sID {
+		if err = interrupted("push", sID[:i], sID[i:]); err != nil {
+			return err
+		}
+		secretUpdate, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", secretsPath, id))
+		if err != nil {
+			return err
+		}
+		value := string(secretUpdate)
+		helpers.AddRedaction(value)
+		changed, err := d.push(ctx, client, id, value, createPrompt)
+		progressPush(d.Profile, id, err, changed)
+		if err != nil {
+			if stopErr := interrupted("push", sID[:i], sID[i:]); stopErr != nil {
+				return stopErr
+			}
+			return err
+		}
+		if changed {
+			pushed = append(pushed, id)
+		}
+	}
+	return nil
+}
+
+// push sets the secret, it reports whether the value was written
+func (d *DopplerManager) push(ctx context.Context, client *doppler.Client, id string, value string, createPrompt bool) (bool, error) {
+	getCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+	current, err := client.Get(getCtx, id)
+	cancelGet()
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case current != nil && (current.Raw == value || current.Computed == value):
+		helpers.Infof("%s %s\n", id, color.CyanString("skipped"))
+		return false, nil
+	case current == nil && !createPrompt && !helpers.PromptYesNo(fmt.Sprintf("%s was not found, would you like to create this secret?", id)):
+		helpers.Infof("creation of %s %s\n", id, color.CyanString("skipped"))
+		return false, nil
+	}
+
+	pushCtx, cancelPush := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancelPush()
+	if err = client.Set(pushCtx, map[string]string{id: value}); err != nil {
+		return false, err
+	}
+	if current == nil {
+		helpers.Infof("%s %s\n", id, color.MagentaString("created"))
+	} else {
+		helpers.Infof("%s %s\n", id, color.YellowString("updated"))
+	}
+	return true, nil
+}
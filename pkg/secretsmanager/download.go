@@ -1,32 +1,85 @@
 package secretsmanager
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"os/exec"
 	"strings"
+
+	"github.com/jacbart/jaws/utils/helpers"
 )
 
-func DownloadSecret(secretID string, secretString string, secretsPath string) error {
-	pattern := strings.Split(secretID, "/")
-	filePath := fmt.Sprintf("%s/%s", secretsPath, secretID)
+// Encrypted reports whether the secret is written encrypted when downloaded
+func (s Secret) Encrypted() bool {
+	return s.recipient != ""
+}
+
+// DownloadSecret writes the secret under secretsPath and returns the path of the file written, secrets
+// from a profile with an age_recipient are encrypted with age and never written in plain text
+func DownloadSecret(s Secret, secretsPath string) (string, error) {
+	pattern := strings.Split(s.ID, "/")
+	filePath := fmt.Sprintf("%s/%s", secretsPath, s.ID)
 	dir := fmt.Sprintf("%s/%s", secretsPath, strings.Join(pattern[:len(pattern)-1], "/"))
 	err := os.MkdirAll(dir, 0755)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if s.Encrypted() {
+		filePath += helpers.AgeExtension
+		if err = encryptToFile(s.recipient, s.Data(), filePath); err != nil {
+			return "", err
+		}
+		return written(s, filePath)
 	}
 	f, err := os.Create(filePath)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer f.Close()
 
-	_, err = f.WriteString(secretString)
+	_, err = f.Write(s.Data())
 	if err != nil {
-		return err
+		return "", err
 	}
 	err = f.Close()
 	if err != nil {
-		return err
+		return "", err
+	}
+	return written(s, filePath)
+}
+
+// Unchanged reports whether the file DownloadSecret would write already holds the secret, encrypted secrets
+// can not be compared and are never unchanged
+func Unchanged(s Secret, secretsPath string) bool {
+	if s.Encrypted() {
+		return false
+	}
+	current, err := os.ReadFile(fmt.Sprintf("%s/%s", secretsPath, s.ID))
+	return err == nil && bytes.Equal(current, s.Data())
+}
+
+// written tracks the downloaded secret file for jaws clean and reports it as progress
+func written(s Secret, filePath string) (string, error) {
+	if err := TrackOutputs(OutputSecrets, filePath); err != nil {
+		return "", err
+	}
+	helpers.Progress(helpers.ProgressEvent{Event: helpers.EventFileWritten, Profile: s.profile, Secret: s.ID, Path: filePath})
+	return filePath, nil
+}
+
+// encryptToFile encrypts content to the age recipient using the age cli
+func encryptToFile(recipient string, content []byte, filePath string) error {
+	if _, err := exec.LookPath("age"); err != nil {
+		return Errorf(KindUnsupported, "age is needed to download secrets for recipient %s but was not found in PATH", recipient)
+	}
+	var stderr bytes.Buffer
+	c := exec.Command("age", "--encrypt", "--recipient", recipient, "--output", filePath)
+	c.Stdin = bytes.NewReader(content)
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		os.Remove(filePath)
+		return fmt.Errorf("age: %w: %s", err, strings.TrimSpace(stderr.String()))
 	}
 	return nil
 }
@@ -0,0 +1,287 @@
+package secretsmanager
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// EnvManager resolves secrets from the process environment and an optional dotenv file,
+// values set in the environment take precedence over the ones in the file
+type EnvManager struct {
+	Profile      string
+	File         string `hcl:"file,optional"`
+	Prefix       string `hcl:"prefix,optional"`
+	AgeRecipient string `hcl:"age_recipient,optional"`
+}
+
+// EnvKey converts a secret ID into an environment variable name, app/db-password becomes APP_DB_PASSWORD
+func EnvKey(secretID string) string {
+	r := strings.NewReplacer("/", "_", "-", "_", ".", "_")
+	return strings.ToUpper(r.Replace(secretID))
+}
+
+// EnvManager Create
+func (e *EnvManager) Create(args []string, secretsPath string, useEditor bool) error {
+	return createLocalSecret(args, secretsPath, useEditor)
+}
+
+// EnvManager Delete removes the selected keys from the dotenv file
+func (e *EnvManager) Delete(scheduleInDays int64) error {
+	if e.File == "" {
+		return Errorf(KindValidation, "env manager %s has no file set, environment variables can not be deleted", e.Profile)
+	}
+	sID, err := e.FuzzyFind(context.Background())
+	if err != nil {
+		return err
+	}
+	if err = requireChange("delete", e.Profile, sID); err != nil {
+		return err
+	}
+	removals := map[string]*string{}
+	for _, id := range sID {
+		removals[e.Prefix+id] = nil
+	}
+	if err = e.writeFile(removals); err != nil {
+		return err
+	}
+	for _, id := range sID {
+		helpers.Infof("%s %s\n", id, color.RedString("deleted"))
+	}
+	notify("delete", e.Profile, sID)
+	return nil
+}
+
+// EnvManager DeleteCancel
+func (e *EnvManager) DeleteCancel(args []string) error {
+	return Errorf(KindUnsupported, "env manager %s does not support scheduled deletions", e.Profile)
+}
+
+// EnvManager FuzzyFind
+func (e *EnvManager) FuzzyFind(ctx context.Context) ([]string, error) {
+	allIDs, err := e.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	return findWithPicks(allIDs, e.Profile)
+}
+
+// EnvManager Get
+func (e *EnvManager) Get(secretsIDList []string) ([]Secret, error) {
+	var Secrets []Secret
+	vars, err := e.vars(false)
+	if err != nil {
+		return []Secret{}, err
+	}
+
+	secretIDs := secretsIDList
+	if len(secretIDs) == 0 {
+		secretIDs, err = e.FuzzyFind(context.Background())
+		if err != nil {
+			return []Secret{}, err
+		}
+	}
+
+	for _, id := range secretIDs {
+		value, ok := vars[id]
+		if !ok {
+			value, ok = vars[EnvKey(id)]
+		}
+		if !ok {
+			continue
+		}
+		helpers.AddRedaction(value)
+		Secrets = append(Secrets, Secret{
+			ID:        id,
+			Content:   value,
+			recipient: e.AgeRecipient,
+			profile:   e.Profile,
+		})
+		helpers.Progress(helpers.ProgressEvent{Event: helpers.EventSecretPulled, Profile: e.Profile, Secret: id})
+	}
+	return Secrets, nil
+}
+
+// EnvManager ListAll
+func (e *EnvManager) ListAll() ([]string, error) {
+	vars, err := e.vars(false)
+	if err != nil {
+		return []string{}, err
+	}
+	list := make([]string, 0, len(vars))
+	for k := range vars {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+// EnvManager Rollback
+func (e *EnvManager) Rollback() error {
+	return Errorf(KindUnsupported, "env manager %s does not keep versions to rollback to", e.Profile)
+}
+
+// EnvManager Set writes local secrets into the dotenv file
+func (e *EnvManager) Set(secretsPath string, createPrompt bool) error {
+	if e.File == "" {
+		return Errorf(KindValidation, "env manager %s has no file set, environment variables can not be set", e.Profile)
+	}
+	current, err := e.vars(true)
+	if err != nil {
+		return err
+	}
+
+	sID, err := helpers.GetSecretNames(secretsPath)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("push", e.Profile, sID); err != nil {
+		return err
+	}
+
+	updates := map[string]*string{}
+	var pushed []string
+	for _, id := range sID {
+		secretUpdate, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", secretsPath, id))
+		if err != nil {
+			return err
+		}
+		value := string(secretUpdate)
+		helpers.AddRedaction(value)
+		key := id
+		if _, ok := current[key]; !ok {
+			key = EnvKey(id)
+		}
+		old, exists := current[key]
+		switch {
+		case exists && old == value:
+			helpers.Infof("%s %s\n", id, color.CyanString("skipped"))
+			progressPush(e.Profile, id, nil, false)
+		case exists:
+			updates[e.Prefix+key] = &value
+			pushed = append(pushed, id)
+			helpers.Infof("%s %s\n", id, color.YellowString("updated"))
+		default:
+			if !createPrompt {
+				e.suggest(id, current)
+				if !helpers.PromptYesNo(fmt.Sprintf("%s was not found, would you like to create this secret?", id)) {
+					helpers.Infof("creation of %s %s\n", id, color.CyanString("skipped"))
+					continue
+				}
+			}
+			updates[e.Prefix+key] = &value
+			pushed = append(pushed, id)
+			helpers.Infof("%s %s\n", id, color.MagentaString("created"))
+		}
+	}
+	err = e.writeFile(updates)
+	for _, id := range pushed {
+		progressPush(e.Profile, id, err, true)
+	}
+	if err != nil {
+		return err
+	}
+	trackRecent(e.Profile, pushed...)
+	notify("push", e.Profile, pushed)
+	return nil
+}
+
+// suggest prints variables similar to the secret ID
+func (e *EnvManager) suggest(secretID string, vars map[string]string) {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	helpers.PrintSuggestions(secretID, helpers.Suggest(EnvKey(secretID), keys))
+}
+
+// vars loads the dotenv file and, unless fileOnly is set, overlays the process environment
+func (e *EnvManager) vars(fileOnly bool) (map[string]string, error) {
+	vars := map[string]string{}
+	if e.File != "" {
+		f, err := os.Open(e.File)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			defer f.Close()
+			fileVars, err := helpers.ParseDotEnv(f)
+			if err != nil {
+				return nil, Errorf(KindValidation, "parsing %s: %w", e.File, err)
+			}
+			for k, v := range fileVars {
+				if strings.HasPrefix(k, e.Prefix) {
+					vars[strings.TrimPrefix(k, e.Prefix)] = v
+				}
+			}
+		}
+	}
+	if fileOnly {
+		return vars, nil
+	}
+	for _, kv := range os.Environ() {
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) != 2 || !strings.HasPrefix(pair[0], e.Prefix) {
+			continue
+		}
+		vars[strings.TrimPrefix(pair[0], e.Prefix)] = pair[1]
+	}
+	return vars, nil
+}
+
+// writeFile applies changes to the dotenv file in place, a nil value removes the key
+func (e *EnvManager) writeFile(changes map[string]*string) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	var lines []string
+	src, err := os.Open(e.File)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		scanner := bufio.NewScanner(src)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		src.Close()
+		if err = scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	written := map[string]bool{}
+	var out []string
+	for _, line := range lines {
+		key, ok := helpers.DotEnvKey(line)
+		value, changed := changes[key]
+		if !ok || !changed {
+			out = append(out, line)
+			continue
+		}
+		if value != nil {
+			out = append(out, helpers.FormatDotEnvLine(key, *value))
+		}
+		written[key] = true
+	}
+	var newKeys []string
+	for key, value := range changes {
+		if !written[key] && value != nil {
+			newKeys = append(newKeys, key)
+		}
+	}
+	sort.Strings(newKeys)
+	for _, key := range newKeys {
+		out = append(out, helpers.FormatDotEnvLine(key, *changes[key]))
+	}
+
+	return ioutil.WriteFile(e.File, []byte(strings.Join(out, "\n")+"\n"), 0600)
+}
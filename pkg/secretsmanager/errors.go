@@ -1,6 +1,22 @@
 package secretsmanager
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/aws/smithy-go"
+	"github.com/jacbart/jaws/internal/azure"
+	"github.com/jacbart/jaws/internal/bitwarden"
+	"github.com/jacbart/jaws/internal/doppler"
+	"github.com/jacbart/jaws/internal/k8s"
+	"github.com/jacbart/jaws/internal/onepassword"
+	"github.com/jacbart/jaws/internal/plugin"
+	"github.com/jacbart/jaws/internal/vault"
+	"github.com/jacbart/jaws/utils/helpers"
+)
 
 type NoConfigFileFound struct {
 	File  string
@@ -17,4 +33,247 @@ type DecodeConfigFailed struct {
 
 func (e *DecodeConfigFailed) Error() string {
 	return fmt.Sprintf("problem decoding %s", e.File)
-}
\ No newline at end of file
+}
+
+// Interrupted is returned by a pull or push stopped by SIGINT or SIGTERM, Done holds the secrets it finished
+// and Aborted the ones it did not get to
+type Interrupted struct {
+	Op      string
+	Done    []string
+	Aborted []string
+}
+
+func (e *Interrupted) Error() string {
+	msg := fmt.Sprintf("%s interrupted, %d secret(s) completed and %d aborted", e.Op, len(e.Done), len(e.Aborted))
+	if len(e.Aborted) != 0 {
+		msg += ": " + strings.Join(e.Aborted, ", ")
+	}
+	return msg
+}
+
+// IsInterrupted reports whether the error comes from a pull or push stopped by a signal
+func IsInterrupted(err error) bool {
+	var interrupted *Interrupted
+	return errors.As(err, &interrupted)
+}
+
+// interrupted returns an Interrupted error when a signal arrived before the operation got to rest, nil otherwise
+func interrupted(op string, done []string, rest []string) error {
+	if !helpers.Interrupted() {
+		return nil
+	}
+	return &Interrupted{Op: op, Done: done, Aborted: rest}
+}
+
+// ErrorKind groups errors by the type of failure so callers can branch on it
+type ErrorKind int
+
+const (
+	KindUnknown ErrorKind = iota
+	KindAuth
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindNetwork
+	KindUnsupported
+	// KindChanged is returned by checks that found something to change, i.e. fmt --check
+	KindChanged
+)
+
+// exit codes returned by the jaws cli for each ErrorKind, 2 is used by commands reporting changes
+var exitCodes = map[ErrorKind]int{
+	KindUnknown:     1,
+	KindChanged:     2,
+	KindAuth:        3,
+	KindNotFound:    4,
+	KindConflict:    5,
+	KindValidation:  6,
+	KindNetwork:     7,
+	KindUnsupported: 8,
+}
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindAuth:
+		return "auth"
+	case KindNotFound:
+		return "not found"
+	case KindConflict:
+		return "conflict"
+	case KindValidation:
+		return "validation"
+	case KindNetwork:
+		return "network"
+	case KindUnsupported:
+		return "unsupported"
+	case KindChanged:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// Error is an error with a known kind
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Errorf formats an error of the given kind, %w can be used to wrap another error
+func Errorf(kind ErrorKind, format string, a ...interface{}) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, a...)}
+}
+
+// KindOf returns the kind of the error, errors coming from the provider SDKs are classified by their error code
+func KindOf(err error) ErrorKind {
+	if err == nil {
+		return KindUnknown
+	}
+	var jawsErr *Error
+	if errors.As(err, &jawsErr) {
+		return jawsErr.Kind
+	}
+	var noConfig *NoConfigFileFound
+	if errors.As(err, &noConfig) {
+		return KindNotFound
+	}
+	var decodeErr *DecodeConfigFailed
+	if errors.As(err, &decodeErr) {
+		return KindValidation
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "AccessDeniedException", "UnrecognizedClientException", "ExpiredTokenException",
+			"InvalidSignatureException", "IncompleteSignature", "InvalidClientTokenId":
+			return KindAuth
+		case "ResourceNotFoundException":
+			return KindNotFound
+		case "ResourceExistsException", "PreconditionNotMetException":
+			return KindConflict
+		case "InvalidParameterException", "InvalidRequestException", "ValidationException",
+			"MalformedPolicyDocumentException":
+			return KindValidation
+		}
+	}
+	var vaultErr *vault.Error
+	if errors.As(err, &vaultErr) {
+		switch vaultErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return KindAuth
+		case http.StatusNotFound:
+			return KindNotFound
+		case http.StatusBadRequest:
+			// vault answers a check-and-set mismatch with a 400
+			for _, e := range vaultErr.Errors {
+				if strings.Contains(e, "check-and-set") {
+					return KindConflict
+				}
+			}
+			return KindValidation
+		case http.StatusPreconditionFailed:
+			return KindConflict
+		}
+	}
+	var azureErr *azure.Error
+	if errors.As(err, &azureErr) {
+		switch azureErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return KindAuth
+		case http.StatusNotFound:
+			return KindNotFound
+		case http.StatusConflict:
+			return KindConflict
+		case http.StatusBadRequest:
+			return KindValidation
+		}
+	}
+	var dopplerErr *doppler.Error
+	if errors.As(err, &dopplerErr) {
+		switch dopplerErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return KindAuth
+		case http.StatusNotFound:
+			return KindNotFound
+		case http.StatusConflict:
+			return KindConflict
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			return KindValidation
+		}
+	}
+	var opErr *onepassword.Error
+	if errors.As(err, &opErr) {
+		switch opErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return KindAuth
+		case http.StatusNotFound:
+			return KindNotFound
+		case http.StatusBadRequest:
+			return KindValidation
+		}
+	}
+	var kubeErr *k8s.Error
+	if errors.As(err, &kubeErr) {
+		switch kubeErr.Reason {
+		case "Unauthorized", "Forbidden":
+			return KindAuth
+		case "NotFound":
+			return KindNotFound
+		case "Conflict", "AlreadyExists":
+			return KindConflict
+		case "Invalid", "BadRequest":
+			return KindValidation
+		}
+	}
+	var bwsErr *bitwarden.Error
+	if errors.As(err, &bwsErr) {
+		msg := strings.ToLower(bwsErr.Stderr)
+		switch {
+		case strings.Contains(msg, "access token"), strings.Contains(msg, "unauthorized"), strings.Contains(msg, "forbidden"):
+			return KindAuth
+		case strings.Contains(msg, "not found"):
+			return KindNotFound
+		}
+	}
+	var pluginErr *plugin.Error
+	if errors.As(err, &pluginErr) {
+		switch pluginErr.Kind {
+		case "auth":
+			return KindAuth
+		case "not_found":
+			return KindNotFound
+		case "conflict":
+			return KindConflict
+		case "validation":
+			return KindValidation
+		case "network":
+			return KindNetwork
+		case "unsupported":
+			return KindUnsupported
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return KindNetwork
+	}
+	return KindUnknown
+}
+
+// ExitCode returns the process exit code matching the kind of the error, 0 for a nil error
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if IsInterrupted(err) {
+		return helpers.SignalExitCode()
+	}
+	return exitCodes[KindOf(err)]
+}
@@ -0,0 +1,52 @@
+package secretsmanager
+
+import "sort"
+
+// EnvMap returns the secrets as variable names mapped to their values without writing them anywhere, names maps
+// a secret ID to the variables it is set as and secrets without a name use EnvKey of their ID. Binary secrets
+// can not be set as variables and two secrets ending up as the same variable, i.e. prod/app-key and
+// prod/app/key both as PROD_APP_KEY, are a conflict naming both.
+func EnvMap(Secrets []Secret, names map[string][]string) (map[string]string, error) {
+	vars := map[string]string{}
+	sources := map[string]string{}
+	for _, s := range Secrets {
+		if s.IsBinary() {
+			return nil, Errorf(KindValidation, "%s is binary and can not be set as an environment variable", s.ID)
+		}
+		keys := names[s.ID]
+		if len(keys) == 0 {
+			keys = []string{EnvKey(s.ID)}
+		}
+		source := s.ID
+		if s.profile != "" {
+			source = PinRef(s.profile, s.ID)
+		}
+		for _, key := range keys {
+			if prev, ok := sources[key]; ok && prev != source {
+				return nil, Errorf(KindConflict, "%s and %s are both set as the environment variable %s, map one to another name with NAME=SECRET", prev, source, key)
+			}
+			sources[key] = source
+			vars[key] = s.Content
+		}
+	}
+	return vars, nil
+}
+
+// EnvVars returns the variables of EnvMap as NAME=value pairs sorted by name, ready to add to the environment
+// of a process
+func EnvVars(Secrets []Secret, names map[string][]string) ([]string, error) {
+	vars, err := EnvMap(Secrets, names)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+vars[key])
+	}
+	return pairs, nil
+}
@@ -0,0 +1,71 @@
+package secretsmanager
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []Secret
+		names   map[string][]string
+		want    map[string]string
+		wantErr ErrorKind
+	}{
+		{
+			name:    "env keys",
+			secrets: []Secret{{ID: "prod/app/db-url", Content: "postgres://"}, {ID: "api.token", Content: "t"}},
+			want:    map[string]string{"PROD_APP_DB_URL": "postgres://", "API_TOKEN": "t"},
+		},
+		{
+			name:    "names",
+			secrets: []Secret{{ID: "prod/app/db-url", Content: "postgres://"}},
+			names:   map[string][]string{"prod/app/db-url": {"DATABASE_URL", "DB_URL"}},
+			want:    map[string]string{"DATABASE_URL": "postgres://", "DB_URL": "postgres://"},
+		},
+		{
+			name:    "same secret twice",
+			secrets: []Secret{{ID: "a/b", Content: "1"}, {ID: "a/b", Content: "1"}},
+			want:    map[string]string{"A_B": "1"},
+		},
+		{
+			name:    "two secrets as one variable",
+			secrets: []Secret{{ID: "app/db-url", Content: "1"}, {ID: "app/db/url", Content: "2"}},
+			wantErr: KindConflict,
+		},
+		{
+			name:    "a name taken by another secret",
+			secrets: []Secret{{ID: "a", Content: "1"}, {ID: "b", Content: "2"}},
+			names:   map[string][]string{"b": {"A"}},
+			wantErr: KindConflict,
+		},
+		{
+			name:    "same secret from two profiles",
+			secrets: []Secret{{ID: "a", Content: "1", profile: "one"}, {ID: "a", Content: "2", profile: "two"}},
+			wantErr: KindConflict,
+		},
+		{
+			name:    "binary",
+			secrets: []Secret{{ID: "cert", Binary: []byte{0xff}}},
+			wantErr: KindValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EnvMap(tt.secrets, tt.names)
+			if tt.wantErr != KindUnknown {
+				if KindOf(err) != tt.wantErr {
+					t.Fatalf("EnvMap error = %v, want kind %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EnvMap error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EnvMap = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+// Package fake provides an in-memory secretsmanager.Manager for testing code built on jaws, or trying jaws as
+// jaws tour does, without access to a real secret manager
+package fake
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jacbart/jaws/pkg/secretsmanager"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// Call is a single recorded call to the fake manager
+type Call struct {
+	Method string
+	Args   []string
+}
+
+// Manager is an in-memory secret manager, secrets are kept in Secrets and any method can be made
+// to fail by setting an error in Errors under the method name, i.e. Errors["Get"]
+type Manager struct {
+	Profile string
+	// Secrets maps secret IDs to their content
+	Secrets map[string]string
+	// Versions maps secret IDs to the content they held before, oldest first
+	Versions map[string][]string
+	// Tags maps secret IDs to their tags
+	Tags map[string]map[string]string
+	// Selected is returned by FuzzyFind in place of an interactive selection
+	Selected []string
+	// Errors maps a method name to the error it returns
+	Errors map[string]error
+	// Scheduled holds the secrets scheduled for deletion with the number of days until they are deleted
+	Scheduled map[string]int64
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var (
+	_ secretsmanager.Manager   = (*Manager)(nil)
+	_ secretsmanager.Tagger    = (*Manager)(nil)
+	_ secretsmanager.Describer = (*Manager)(nil)
+	_ secretsmanager.Trasher   = (*Manager)(nil)
+	_ secretsmanager.Historian = (*Manager)(nil)
+)
+
+// New returns a fake manager holding a copy of the given secrets
+func New(profile string, secrets map[string]string) *Manager {
+	m := &Manager{
+		Profile:   profile,
+		Secrets:   map[string]string{},
+		Versions:  map[string][]string{},
+		Tags:      map[string]map[string]string{},
+		Errors:    map[string]error{},
+		Scheduled: map[string]int64{},
+	}
+	for id, content := range secrets {
+		m.Secrets[id] = content
+	}
+	return m
+}
+
+// Calls returns the calls made to the manager in order
+func (m *Manager) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// record saves the call and returns the error injected for the method, if any
+func (m *Manager) record(method string, args ...string) error {
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+	return m.Errors[method]
+}
+
+// ProfileName
+func (m *Manager) ProfileName() string {
+	return m.Profile
+}
+
+// Create adds an empty secret for the first argument
+func (m *Manager) Create(args []string, secretsPath string, useEditor bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("Create", args...); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return secretsmanager.Errorf(secretsmanager.KindValidation, "no secret name given")
+	}
+	if _, ok := m.Secrets[args[0]]; ok {
+		return secretsmanager.Errorf(secretsmanager.KindConflict, "secret %s already exists", args[0])
+	}
+	m.Secrets[args[0]] = ""
+	return nil
+}
+
+// Delete schedules the selected secrets for deletion
+func (m *Manager) Delete(scheduleInDays int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("Delete", fmt.Sprint(scheduleInDays)); err != nil {
+		return err
+	}
+	for _, id := range m.Selected {
+		if _, ok := m.Secrets[id]; !ok {
+			return secretsmanager.Errorf(secretsmanager.KindNotFound, "secret %s not found", id)
+		}
+		m.Scheduled[id] = scheduleInDays
+	}
+	return nil
+}
+
+// DeleteCancel cancels the scheduled deletion of the given secrets
+func (m *Manager) DeleteCancel(args []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("DeleteCancel", args...); err != nil {
+		return err
+	}
+	for _, id := range args {
+		if _, ok := m.Scheduled[id]; !ok {
+			return secretsmanager.Errorf(secretsmanager.KindNotFound, "secret %s is not scheduled for deletion", id)
+		}
+		delete(m.Scheduled, id)
+	}
+	return nil
+}
+
+// FuzzyFind returns Selected
+func (m *Manager) FuzzyFind(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("FuzzyFind"); err != nil {
+		return nil, err
+	}
+	return append([]string(nil), m.Selected...), nil
+}
+
+// Get returns the requested secrets, or the selected ones when none are requested. Missing secrets are skipped.
+func (m *Manager) Get(secretsIDList []string) ([]secretsmanager.Secret, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("Get", secretsIDList...); err != nil {
+		return []secretsmanager.Secret{}, err
+	}
+	ids := secretsIDList
+	if len(ids) == 0 {
+		ids = m.Selected
+	}
+	var Secrets []secretsmanager.Secret
+	for _, id := range ids {
+		if content, ok := m.Secrets[id]; ok {
+			Secrets = append(Secrets, secretsmanager.Secret{ID: id, Content: content})
+		}
+	}
+	return Secrets, nil
+}
+
+// ListAll returns the sorted IDs of every secret
+func (m *Manager) ListAll() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("ListAll"); err != nil {
+		return []string{}, err
+	}
+	return m.ids(), nil
+}
+
+// ListInfo returns every secret with its tags
+func (m *Manager) ListInfo() ([]secretsmanager.SecretInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("ListInfo"); err != nil {
+		return nil, err
+	}
+	var infos []secretsmanager.SecretInfo
+	for _, id := range m.ids() {
+		tags := map[string]string{}
+		for k, v := range m.Tags[id] {
+			tags[k] = v
+		}
+		infos = append(infos, secretsmanager.SecretInfo{ID: id, Tags: tags})
+	}
+	return infos, nil
+}
+
+// ListTrash returns the secrets scheduled for deletion
+func (m *Manager) ListTrash() ([]secretsmanager.TrashedSecret, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("ListTrash"); err != nil {
+		return nil, err
+	}
+	var trashed []secretsmanager.TrashedSecret
+	for _, id := range m.ids() {
+		if days, ok := m.Scheduled[id]; ok {
+			trashed = append(trashed, secretsmanager.TrashedSecret{
+				ID:           id,
+				Profile:      m.Profile,
+				DeletionDate: time.Now().Add(time.Duration(days) * 24 * time.Hour),
+			})
+		}
+	}
+	return trashed, nil
+}
+
+// Rollback restores the previous content of the selected secrets as their newest version
+func (m *Manager) Rollback() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("Rollback", m.Selected...); err != nil {
+		return err
+	}
+	for _, id := range m.Selected {
+		older := m.Versions[id]
+		if len(older) == 0 {
+			return secretsmanager.Errorf(secretsmanager.KindValidation, "%s has no older version to roll back to", id)
+		}
+		m.put(id, older[len(older)-1])
+	}
+	return nil
+}
+
+// History returns the versions of the secret newest first, numbered from 1 for the oldest
+func (m *Manager) History(id string) ([]secretsmanager.SecretVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("History", id); err != nil {
+		return nil, err
+	}
+	if _, ok := m.Secrets[id]; !ok {
+		return nil, secretsmanager.Errorf(secretsmanager.KindNotFound, "secret %s not found", id)
+	}
+	current := len(m.Versions[id]) + 1
+	versions := []secretsmanager.SecretVersion{{Version: strconv.Itoa(current), Stages: []string{"current"}}}
+	for n := current - 1; n > 0; n-- {
+		versions = append(versions, secretsmanager.SecretVersion{Version: strconv.Itoa(n)})
+	}
+	return versions, nil
+}
+
+// GetVersion returns the content the secret held in the version
+func (m *Manager) GetVersion(id string, version string) (secretsmanager.Secret, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("GetVersion", id, version); err != nil {
+		return secretsmanager.Secret{}, err
+	}
+	content, err := m.version(id, version)
+	if err != nil {
+		return secretsmanager.Secret{}, err
+	}
+	return secretsmanager.Secret{ID: id, Content: content}, nil
+}
+
+// RestoreVersion stores the content of the version as the newest version of the secret
+func (m *Manager) RestoreVersion(id string, version string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("RestoreVersion", id, version); err != nil {
+		return err
+	}
+	content, err := m.version(id, version)
+	if err != nil {
+		return err
+	}
+	m.put(id, content)
+	return nil
+}
+
+// Set stores every file found under secretsPath as a secret
+func (m *Manager) Set(secretsPath string, createPrompt bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("Set", secretsPath); err != nil {
+		return err
+	}
+	sID, err := helpers.GetSecretNames(secretsPath)
+	if err != nil {
+		return err
+	}
+	for _, id := range sID {
+		content, err := os.ReadFile(fmt.Sprintf("%s/%s", secretsPath, id))
+		if err != nil {
+			return err
+		}
+		m.put(id, string(content))
+	}
+	return nil
+}
+
+// TagSecrets sets the tags on each secret
+func (m *Manager) TagSecrets(ids []string, tags map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("TagSecrets", ids...); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if m.Tags[id] == nil {
+			m.Tags[id] = map[string]string{}
+		}
+		for k, v := range tags {
+			m.Tags[id][k] = v
+		}
+	}
+	return nil
+}
+
+// UntagSecrets removes the tag keys from each secret
+func (m *Manager) UntagSecrets(ids []string, keys []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.record("UntagSecrets", ids...); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		for _, k := range keys {
+			delete(m.Tags[id], k)
+		}
+	}
+	return nil
+}
+
+// put stores the content as the newest version of the secret, the content it replaces is kept in Versions
+func (m *Manager) put(id string, content string) {
+	if current, ok := m.Secrets[id]; ok {
+		if current == content {
+			return
+		}
+		m.Versions[id] = append(m.Versions[id], current)
+	}
+	m.Secrets[id] = content
+}
+
+// version returns the content of the numbered version of the secret
+func (m *Manager) version(id string, version string) (string, error) {
+	current, ok := m.Secrets[id]
+	if !ok {
+		return "", secretsmanager.Errorf(secretsmanager.KindNotFound, "secret %s not found", id)
+	}
+	older := m.Versions[id]
+	n, err := strconv.Atoi(version)
+	switch {
+	case err != nil || n < 1 || n > len(older)+1:
+		return "", secretsmanager.Errorf(secretsmanager.KindNotFound, "%s has no version %s", id, version)
+	case n == len(older)+1:
+		return current, nil
+	}
+	return older[n-1], nil
+}
+
+func (m *Manager) ids() []string {
+	ids := make([]string, 0, len(m.Secrets))
+	for id := range m.Secrets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
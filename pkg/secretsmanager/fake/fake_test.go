@@ -0,0 +1,60 @@
+package fake
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestVersions(t *testing.T) {
+	m := New("test", map[string]string{"app/db": "one"})
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "app"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{"two", "two", "three"} {
+		if err := os.WriteFile(filepath.Join(dir, "app/db"), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Set(dir, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := m.History("app/db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var numbers []string
+	for _, v := range versions {
+		numbers = append(numbers, v.Version)
+	}
+	if want := []string{"3", "2", "1"}; !reflect.DeepEqual(numbers, want) {
+		t.Fatalf("History = %q, want %q", numbers, want)
+	}
+	if !versions[0].Current() {
+		t.Errorf("newest version is not current: %+v", versions[0])
+	}
+	if s, err := m.GetVersion("app/db", "1"); err != nil || s.Content != "one" {
+		t.Errorf("GetVersion 1 = %q, %v, want one", s.Content, err)
+	}
+	if _, err = m.GetVersion("app/db", "4"); err == nil {
+		t.Error("GetVersion of a missing version succeeded")
+	}
+
+	if err = m.RestoreVersion("app/db", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if m.Secrets["app/db"] != "one" || len(m.Versions["app/db"]) != 3 {
+		t.Errorf("after restore content %q with %d older versions, want one with 3", m.Secrets["app/db"], len(m.Versions["app/db"]))
+	}
+
+	m.Selected = []string{"app/db"}
+	if err = m.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Secrets["app/db"] != "three" {
+		t.Errorf("after rollback content %q, want three", m.Secrets["app/db"])
+	}
+}
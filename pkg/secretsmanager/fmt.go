@@ -0,0 +1,33 @@
+package secretsmanager
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// FormatFile rewrites an HCL file like jaws.conf in its canonical format, it reports whether the file
+// was not formatted. With write set to false the file is only checked.
+func FormatFile(path string, write bool) (bool, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	if _, diag := hclwrite.ParseConfig(src, path, hcl.InitialPos); diag.HasErrors() {
+		return false, Errorf(KindValidation, "%s: %w", path, diag)
+	}
+	formatted := hclwrite.Format(src)
+	if bytes.Equal(src, formatted) {
+		return false, nil
+	}
+	if !write {
+		return true, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return true, err
+	}
+	return true, os.WriteFile(path, formatted, info.Mode().Perm())
+}
@@ -5,20 +5,39 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
 	"github.com/fatih/color"
+	"github.com/jacbart/jaws/utils/helpers"
 )
 
 type Secret struct {
 	ID      string
 	Content string
+	// Binary holds the value of secrets stored as bytes instead of a string, Content is empty for them
+	Binary []byte
+	// recipient is the age recipient of the profile the secret came from, downloads are encrypted when set
+	recipient string
+	// profile is the profile the secret came from
+	profile string
 }
 
-// AWSManager Get
+// IsBinary reports whether the secret holds bytes instead of a string
+func (s Secret) IsBinary() bool {
+	return s.Binary != nil
+}
+
+// Data returns the value of the secret as it is written to a file
+func (s Secret) Data() []byte {
+	if s.IsBinary() {
+		return s.Binary
+	}
+	return []byte(s.Content)
+}
+
+// AWSManager Get, secrets only holding a SecretBinary are returned with Binary set
 func (a *AWSManager) Get(secretsIDList []string) ([]Secret, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(helpers.Context())
 	defer cancel()
 	var Secrets []Secret
 
@@ -44,23 +63,112 @@ func (a *AWSManager) Get(secretsIDList []string) ([]Secret, error) {
 	l := len(secretIDs)
 	var rnfErr *types.ResourceNotFoundException
 
+	defer helpers.StartWork()()
 	for i := 0; i < l; i++ {
-		vin := &secretsmanager.GetSecretValueInput{
-			SecretId: aws.String(secretIDs[i]),
+		if err = interrupted("pull", secretIDs[:i], secretIDs[i:]); err != nil {
+			return Secrets, err
 		}
-		vout, err := client.GetSecretValue(ctx, vin)
-		if err != nil {
-			if errors.As(err, &rnfErr) {
-				fmt.Printf("%s %s", color.RedString("no secret found called"), color.RedString(secretIDs[i]))
-			} else {
+		value, cached := a.cache.get(secretIDs[i])
+		if !cached {
+			vin := &secretsmanager.GetSecretValueInput{
+				SecretId: &secretIDs[i],
+			}
+			timeCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+			vout, err := client.GetSecretValue(timeCtx, vin)
+			cancelGet()
+			if err != nil && !errors.As(err, &rnfErr) {
+				if stopErr := interrupted("pull", secretIDs[:i], secretIDs[i:]); stopErr != nil {
+					return Secrets, stopErr
+				}
 				return []Secret{}, err
 			}
+			if err == nil {
+				value = secretValue(vout)
+			}
+			a.cache.put(secretIDs[i], value)
+		}
+		if value == nil {
+			continue
+		}
+		if !value.IsBinary() {
+			helpers.AddRedaction(value.Content)
 		}
 		Secrets = append(Secrets, Secret{
-			ID:      secretIDs[i],
-			Content: *vout.SecretString,
+			ID:        secretIDs[i],
+			Content:   value.Content,
+			Binary:    value.Binary,
+			recipient: a.AgeRecipient,
+			profile:   a.Profile,
 		})
+		helpers.Progress(helpers.ProgressEvent{Event: helpers.EventSecretPulled, Profile: a.Profile, Secret: secretIDs[i]})
 	}
 
 	return Secrets, nil
 }
+
+// secretValue returns the string or, without one, the binary value of the secret, nil when it has neither
+func secretValue(vout *secretsmanager.GetSecretValueOutput) *Secret {
+	switch {
+	case vout.SecretString != nil:
+		return &Secret{Content: *vout.SecretString}
+	case vout.SecretBinary != nil:
+		return &Secret{Binary: vout.SecretBinary}
+	}
+	return nil
+}
+
+// PrintMissing prints a notice for each requested secret reference that was not found
+func PrintMissing(refs []string, Secrets []Secret) {
+	for _, ref := range MissingRefs(refs, Secrets) {
+		fmt.Fprintf(helpers.InfoOutput, "%s %s\n", color.RedString("no secret found called"), color.RedString(ref))
+	}
+}
+
+// SuggestMissing prints similar secret IDs from the matching profile for each reference that was not found
+func SuggestMissing(managers []Manager, defaultManager Manager, refs []string, Secrets []Secret) error {
+	missing := MissingRefs(refs, Secrets)
+	if len(missing) == 0 || helpers.SuggestDistance < 0 {
+		return nil
+	}
+	lists := map[Manager][]string{}
+	for _, ref := range missing {
+		profile, id := ParseSecretRef(ref)
+		m := defaultManager
+		if profile != "" {
+			var err error
+			if m, err = FindManager(managers, profile); err != nil {
+				return err
+			}
+		}
+		if _, ok := lists[m]; !ok {
+			list, err := m.ListAll()
+			if err != nil {
+				return err
+			}
+			lists[m] = list
+		}
+		suggestions := helpers.Suggest(id, lists[m])
+		if profile != "" {
+			for i := range suggestions {
+				suggestions[i] = profile + refSeparator + suggestions[i]
+			}
+		}
+		helpers.PrintSuggestions(ref, suggestions)
+	}
+	return nil
+}
+
+// missingRefs returns the references whose secret ID is not part of the returned secrets
+func MissingRefs(refs []string, Secrets []Secret) []string {
+	found := map[string]bool{}
+	for _, s := range Secrets {
+		found[s.ID] = true
+	}
+	var missing []string
+	for _, ref := range refs {
+		if _, id := ParseSecretRef(ref); !found[id] {
+			missing = append(missing, ref)
+		}
+	}
+	return missing
+}
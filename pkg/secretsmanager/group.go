@@ -0,0 +1,96 @@
+package secretsmanager
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// groupPrefix marks a secret reference as the name of a group, i.e. @payments-prod
+const groupPrefix = "@"
+
+// GroupHCL names a set of secrets so it can be used as @name instead of listing every secret
+type GroupHCL struct {
+	Name     string   `hcl:"name,label"`
+	IDs      []string `hcl:"ids,optional"`
+	Prefixes []string `hcl:"prefixes,optional"`
+}
+
+var groups = map[string]GroupHCL{}
+
+// SetGroups sets the groups that can be referenced with @name
+func SetGroups(g []GroupHCL) error {
+	groups = map[string]GroupHCL{}
+	for _, group := range g {
+		if _, ok := groups[group.Name]; ok {
+			return Errorf(KindValidation, "group `%s` is defined more than once", group.Name)
+		}
+		if len(group.IDs) == 0 && len(group.Prefixes) == 0 {
+			return Errorf(KindValidation, "group `%s` needs ids or prefixes", group.Name)
+		}
+		groups[group.Name] = group
+	}
+	return nil
+}
+
+// GroupNames returns the sorted names of the configured groups
+func GroupNames() []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpandGroups replaces every @name reference with the secrets of the group, prefixes are listed from the manager
+// and the IDs are kept as written so they can pin a profile. Other references are returned unchanged.
+func ExpandGroups(m Manager, refs []string) ([]string, error) {
+	var expanded []string
+	for _, ref := range refs {
+		if !strings.HasPrefix(ref, groupPrefix) {
+			expanded = append(expanded, ref)
+			continue
+		}
+		name := strings.TrimPrefix(ref, groupPrefix)
+		group, ok := groups[name]
+		if !ok {
+			helpers.PrintSuggestions(ref, prefixAll(groupPrefix, helpers.Suggest(name, GroupNames())))
+			return nil, Errorf(KindNotFound, "unknown group `%s`", ref)
+		}
+		expanded = append(expanded, group.IDs...)
+		if len(group.Prefixes) == 0 {
+			continue
+		}
+		infos, err := ListQuery(m, Query{Prefixes: group.Prefixes})
+		if err != nil {
+			return nil, err
+		}
+		if len(infos) == 0 && len(group.IDs) == 0 {
+			return nil, Errorf(KindNotFound, "no secrets in %s match group `%s`", m.ProfileName(), ref)
+		}
+		expanded = append(expanded, secretIDs(infos)...)
+	}
+	return dedupe(expanded), nil
+}
+
+func prefixAll(prefix string, values []string) []string {
+	prefixed := make([]string, 0, len(values))
+	for _, v := range values {
+		prefixed = append(prefixed, prefix+v)
+	}
+	return prefixed
+}
+
+func dedupe(values []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
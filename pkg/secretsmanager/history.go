@@ -0,0 +1,74 @@
+package secretsmanager
+
+import (
+	"time"
+
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// maxHistory caps how many commands are kept in the history
+const maxHistory = 200
+
+// HistoryEntry is a jaws command that was run, along with the parameters resolved from the config for it.
+// jaws does not take secret values as arguments so none end up in the history.
+type HistoryEntry struct {
+	N           int       `json:"n"`
+	Time        time.Time `json:"time"`
+	Dir         string    `json:"dir"`
+	Args        []string  `json:"args"`
+	Config      string    `json:"config,omitempty"`
+	Profile     string    `json:"profile,omitempty"`
+	SecretsPath string    `json:"secrets_path,omitempty"`
+	ExitCode    int       `json:"exit_code"`
+}
+
+// historyFile returns the path of the state file holding the command history
+func historyFile() string {
+	return helpers.StatePath("history.json")
+}
+
+// LoadHistory reads the command history, oldest first
+func LoadHistory() ([]HistoryEntry, error) {
+	var history []HistoryEntry
+	if err := loadState(historyFile(), &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// RecordHistory appends the command to the history, numbering it after the last one
+func RecordHistory(entry HistoryEntry) error {
+	history, err := LoadHistory()
+	if err != nil {
+		return err
+	}
+	entry.N = 1
+	if len(history) != 0 {
+		entry.N = history[len(history)-1].N + 1
+	}
+	history = append(history, entry)
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	return saveState(historyFile(), history)
+}
+
+// FindHistory returns the command numbered n, 0 returns the last command
+func FindHistory(n int) (HistoryEntry, error) {
+	history, err := LoadHistory()
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	if len(history) == 0 {
+		return HistoryEntry{}, Errorf(KindNotFound, "the history is empty")
+	}
+	if n == 0 {
+		return history[len(history)-1], nil
+	}
+	for _, entry := range history {
+		if entry.N == n {
+			return entry, nil
+		}
+	}
+	return HistoryEntry{}, Errorf(KindNotFound, "no command numbered %d in the history, the oldest is %d", n, history[0].N)
+}
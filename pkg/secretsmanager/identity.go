@@ -0,0 +1,25 @@
+package secretsmanager
+
+import (
+	"context"
+
+	"github.com/jacbart/jaws/internal/aws"
+)
+
+// identity returns who is making changes, the changed_by setting of the manager or the arn of the aws caller identity
+func (a *AWSManager) identity(ctx context.Context) (string, error) {
+	if a.ChangedBy != "" {
+		return a.ChangedBy, nil
+	}
+	if a.callerARN != "" {
+		return a.callerARN, nil
+	}
+	cfg, err := loadAWSConfig(a, ctx)
+	if err != nil {
+		return "", err
+	}
+	if a.callerARN, err = aws.CallerIdentity(ctx, cfg); err != nil {
+		return "", err
+	}
+	return a.callerARN, nil
+}
@@ -0,0 +1,170 @@
+package secretsmanager
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Actions an import takes for a row
+const (
+	ImportCreate    = "create"
+	ImportUpdate    = "update"
+	ImportUnchanged = "unchanged"
+)
+
+// ImportRow is one secret of a csv import, Line is the line of the row in the file
+type ImportRow struct {
+	Line        int
+	ID          string
+	Value       string
+	Description string
+	Tags        map[string]string
+}
+
+// ReadImport reads name,value(,description,tags) rows, a first row naming the columns is skipped. Tags are
+// key=value pairs separated by commas, i.e. "team=payments,env=prod", and the prefix is added to every name.
+func ReadImport(r io.Reader, comma rune, prefix string) ([]ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = comma == '\t'
+	var rows []ImportRow
+	lines := map[string]int{}
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, Errorf(KindValidation, "%w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		if first && len(record) >= 2 && strings.EqualFold(record[0], "name") && strings.EqualFold(record[1], "value") {
+			continue
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(record) < 2 || len(record) > 4 {
+			return nil, Errorf(KindValidation, "line %d: expected name,value(,description,tags), got %d columns", line, len(record))
+		}
+		name := strings.TrimSpace(record[0])
+		if name == "" {
+			return nil, Errorf(KindValidation, "line %d: the name is empty", line)
+		}
+		row := ImportRow{Line: line, ID: prefix + name, Value: record[1]}
+		if len(record) > 2 {
+			row.Description = strings.TrimSpace(record[2])
+		}
+		if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+			row.Tags = map[string]string{}
+			for _, pair := range strings.Split(record[3], ",") {
+				kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+				if len(kv) != 2 || kv[0] == "" {
+					return nil, Errorf(KindValidation, "line %d: tag `%s` is not key=value", line, pair)
+				}
+				row.Tags[kv[0]] = kv[1]
+			}
+		}
+		if prev, ok := lines[row.ID]; ok {
+			return nil, Errorf(KindValidation, "line %d: %s is already imported on line %d", line, row.ID, prev)
+		}
+		lines[row.ID] = line
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// PlanImport compares the rows with the secrets of the manager and returns the action of each row
+func PlanImport(m Manager, rows []ImportRow) ([]string, error) {
+	existing, err := m.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	listed := map[string]bool{}
+	for _, id := range existing {
+		listed[id] = true
+	}
+	var ids []string
+	for _, row := range rows {
+		if listed[row.ID] {
+			ids = append(ids, row.ID)
+		}
+	}
+	current := map[string]string{}
+	if len(ids) != 0 {
+		Secrets, err := m.Get(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range Secrets {
+			current[s.ID] = s.Content
+		}
+	}
+	actions := make([]string, len(rows))
+	for i, row := range rows {
+		value, ok := current[row.ID]
+		switch {
+		case !listed[row.ID]:
+			actions[i] = ImportCreate
+		case ok && value == row.Value:
+			actions[i] = ImportUnchanged
+		default:
+			actions[i] = ImportUpdate
+		}
+	}
+	return actions, nil
+}
+
+// Import pushes the values of the rows, creating missing secrets, then sets the tags and descriptions of the
+// rows giving them. Managers without tags or descriptions only get the values.
+func Import(m Manager, rows []ImportRow) error {
+	values := map[string]string{}
+	for _, row := range rows {
+		values[row.ID] = row.Value
+	}
+	if err := PushValues(m, values, true); err != nil {
+		return err
+	}
+	// a chain implements both and answers unsupported when its first profile does not
+	tagger, canTag := m.(Tagger)
+	describer, canDescribe := m.(DescriptionSetter)
+	var skippedTags, skippedDescriptions bool
+	for _, row := range rows {
+		if len(row.Tags) != 0 && !skippedTags {
+			var err error
+			if canTag {
+				err = tagger.TagSecrets([]string{row.ID}, row.Tags)
+			}
+			switch {
+			case !canTag || KindOf(err) == KindUnsupported:
+				skippedTags = true
+			case err != nil:
+				return err
+			}
+		}
+		if row.Description != "" && !skippedDescriptions {
+			var err error
+			if canDescribe {
+				err = describer.SetDescription(row.ID, row.Description)
+			}
+			switch {
+			case !canDescribe || KindOf(err) == KindUnsupported:
+				skippedDescriptions = true
+			case err != nil:
+				return err
+			}
+		}
+	}
+	if skippedTags {
+		color.Yellow("profile %s does not support tags, the tags column was not imported", m.ProfileName())
+	}
+	if skippedDescriptions {
+		color.Yellow("profile %s does not support descriptions, the description column was not imported", m.ProfileName())
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package secretsmanager_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jacbart/jaws/pkg/secretsmanager"
+	"github.com/jacbart/jaws/pkg/secretsmanager/fake"
+)
+
+func TestReadImport(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		comma   rune
+		prefix  string
+		want    []secretsmanager.ImportRow
+		wantErr bool
+	}{
+		{
+			name:  "header and columns",
+			input: "name,value,description,tags\ndb-password,s3cret,the database,\"team=payments,env=prod\"\napi-key,k\n",
+			comma: ',',
+			want: []secretsmanager.ImportRow{
+				{Line: 2, ID: "db-password", Value: "s3cret", Description: "the database", Tags: map[string]string{"team": "payments", "env": "prod"}},
+				{Line: 3, ID: "api-key", Value: "k"},
+			},
+		},
+		{
+			name:   "prefix and quoted values",
+			input:  "db-url,\"postgres://u:p@h/db?a=1,b=2\"\n",
+			comma:  ',',
+			prefix: "team/app/",
+			want:   []secretsmanager.ImportRow{{Line: 1, ID: "team/app/db-url", Value: "postgres://u:p@h/db?a=1,b=2"}},
+		},
+		{
+			name:  "tsv",
+			input: "token\tab\"c\n",
+			comma: '\t',
+			want:  []secretsmanager.ImportRow{{Line: 1, ID: "token", Value: `ab"c`}},
+		},
+		{name: "one column", input: "lonely\n", comma: ',', wantErr: true},
+		{name: "five columns", input: "a,b,c,d,e\n", comma: ',', wantErr: true},
+		{name: "empty name", input: " ,value\n", comma: ',', wantErr: true},
+		{name: "bad tag", input: "a,b,,team\n", comma: ',', wantErr: true},
+		{name: "duplicate", input: "a,1\na,2\n", comma: ',', wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secretsmanager.ReadImport(strings.NewReader(tt.input), tt.comma, tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadImport error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if secretsmanager.KindOf(err) != secretsmanager.KindValidation {
+					t.Errorf("ReadImport error kind = %v, want validation", secretsmanager.KindOf(err))
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadImport = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	m := fake.New("test", map[string]string{"app/same": "1", "app/changed": "old"})
+	rows := []secretsmanager.ImportRow{
+		{Line: 1, ID: "app/same", Value: "1"},
+		{Line: 2, ID: "app/changed", Value: "new"},
+		{Line: 3, ID: "app/new", Value: "fresh", Tags: map[string]string{"team": "payments"}},
+	}
+	actions, err := secretsmanager.PlanImport(m, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{secretsmanager.ImportUnchanged, secretsmanager.ImportUpdate, secretsmanager.ImportCreate}
+	if !reflect.DeepEqual(actions, want) {
+		t.Errorf("PlanImport = %q, want %q", actions, want)
+	}
+	for _, c := range m.Calls() {
+		if c.Method == "Set" || c.Method == "TagSecrets" {
+			t.Fatalf("PlanImport changed secrets with %s", c.Method)
+		}
+	}
+
+	if err = secretsmanager.Import(m, rows); err != nil {
+		t.Fatal(err)
+	}
+	wantSecrets := map[string]string{"app/same": "1", "app/changed": "new", "app/new": "fresh"}
+	if !reflect.DeepEqual(m.Secrets, wantSecrets) {
+		t.Errorf("secrets after import = %q, want %q", m.Secrets, wantSecrets)
+	}
+	if got := m.Tags["app/new"]["team"]; got != "payments" {
+		t.Errorf("tag team of app/new = %q, want payments", got)
+	}
+}
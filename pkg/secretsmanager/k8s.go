@@ -0,0 +1,362 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/internal/k8s"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// K8sManager stores secrets as keys of Kubernetes Secret objects using kubectl and the current kubeconfig
+// context. Secret IDs are namespace/name/key so each key downloads to its own file.
+type K8sManager struct {
+	Profile      string
+	Namespace    string   `hcl:"namespace,optional"`
+	Context      string   `hcl:"context,optional"`
+	Kubeconfig   string   `hcl:"kubeconfig,optional"`
+	Kubectl      string   `hcl:"kubectl,optional"`
+	Types        []string `hcl:"types,optional"`
+	AgeRecipient string   `hcl:"age_recipient,optional"`
+}
+
+func (k *K8sManager) kubectl() *k8s.Kubectl {
+	return &k8s.Kubectl{Path: k.Kubectl, Kubeconfig: k.Kubeconfig, Context: k.Context}
+}
+
+// namespace returns the configured namespace, the one of the kubeconfig context otherwise
+func (k *K8sManager) namespace(ctx context.Context) (string, error) {
+	if k.Namespace == "" {
+		ns, err := k.kubectl().Namespace(ctx)
+		if err != nil {
+			return "", err
+		}
+		k.Namespace = ns
+	}
+	return k.Namespace, nil
+}
+
+// listed reports whether secrets of the type are listed, only Opaque secrets without types set
+func (k *K8sManager) listed(secretType string) bool {
+	if len(k.Types) == 0 {
+		return secretType == "Opaque"
+	}
+	return helpers.Contains(k.Types, secretType)
+}
+
+// k8sRef is a secret ID split into the object and the key of its data
+type k8sRef struct {
+	namespace string
+	name      string
+	key       string
+}
+
+func (r k8sRef) object() string {
+	return r.namespace + "/" + r.name
+}
+
+func parseK8sRef(secretID string) (k8sRef, error) {
+	parts := strings.SplitN(secretID, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" || strings.Contains(parts[2], "/") {
+		return k8sRef{}, Errorf(KindValidation, "%s is not a kubernetes secret, IDs are namespace/name/key", secretID)
+	}
+	return k8sRef{namespace: parts[0], name: parts[1], key: parts[2]}, nil
+}
+
+// K8sManager Create
+func (k *K8sManager) Create(args []string, secretsPath string, useEditor bool) error {
+	return createLocalSecret(args, secretsPath, useEditor)
+}
+
+// K8sManager Delete removes the selected keys from their secrets, a secret left without keys is deleted.
+// Kubernetes has no scheduled deletions so the number of days is not used.
+func (k *K8sManager) Delete(scheduleInDays int64) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sID, err := k.FuzzyFind(ctx)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("delete", k.Profile, sID); err != nil {
+		return err
+	}
+	refs := map[string][]k8sRef{}
+	var objects []string
+	for _, id := range sID {
+		ref, err := parseK8sRef(id)
+		if err != nil {
+			return err
+		}
+		if _, ok := refs[ref.object()]; !ok {
+			objects = append(objects, ref.object())
+		}
+		refs[ref.object()] = append(refs[ref.object()], ref)
+	}
+
+	kubectl := k.kubectl()
+	var deleted []string
+	defer func() { notify("delete", k.Profile, deleted) }()
+	for _, object := range objects {
+		first := refs[object][0]
+		timeCtx, cancelDelete := context.WithTimeout(ctx, helpers.PushTimeout)
+		s, err := kubectl.Get(timeCtx, first.namespace, first.name)
+		if err == nil && s != nil {
+			for _, ref := range refs[object] {
+				s.DeleteKey(ref.key)
+			}
+			if len(s.Keys()) == 0 {
+				err = kubectl.Delete(timeCtx, first.namespace, first.name)
+			} else {
+				err = kubectl.Replace(timeCtx, s)
+			}
+		}
+		cancelDelete()
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs[object] {
+			id := ref.object() + "/" + ref.key
+			deleted = append(deleted, id)
+			helpers.Infof("%s %s\n", id, color.RedString("deleted"))
+		}
+	}
+	return nil
+}
+
+// K8sManager DeleteCancel
+func (k *K8sManager) DeleteCancel(args []string) error {
+	return Errorf(KindUnsupported, "k8s manager %s does not support scheduled deletions", k.Profile)
+}
+
+// K8sManager FuzzyFind
+func (k *K8sManager) FuzzyFind(ctx context.Context) ([]string, error) {
+	allIDs, err := k.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	return findWithPicks(allIDs, k.Profile)
+}
+
+// K8sManager Get
+func (k *K8sManager) Get(secretsIDList []string) ([]Secret, error) {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	var err error
+	secretIDs := secretsIDList
+	if len(secretIDs) == 0 {
+		if secretIDs, err = k.FuzzyFind(ctx); err != nil {
+			return []Secret{}, err
+		}
+	}
+
+	kubectl := k.kubectl()
+	objects := map[string]k8s.Secret{}
+	var Secrets []Secret
+	defer helpers.StartWork()()
+	for i, id := range secretIDs {
+		if err = interrupted("pull", secretIDs[:i], secretIDs[i:]); err != nil {
+			return Secrets, err
+		}
+		ref, err := parseK8sRef(id)
+		if err != nil {
+			// an ID kubernetes can not hold is never found
+			continue
+		}
+		s, fetched := objects[ref.object()]
+		if !fetched {
+			timeCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+			s, err = kubectl.Get(timeCtx, ref.namespace, ref.name)
+			cancelGet()
+			if err != nil {
+				if stopErr := interrupted("pull", secretIDs[:i], secretIDs[i:]); stopErr != nil {
+					return Secrets, stopErr
+				}
+				return []Secret{}, err
+			}
+			objects[ref.object()] = s
+		}
+		if s == nil {
+			continue
+		}
+		value, ok, err := s.Value(ref.key)
+		if err != nil {
+			return []Secret{}, fmt.Errorf("decoding %s: %w", id, err)
+		}
+		if !ok {
+			continue
+		}
+		helpers.AddRedaction(value)
+		Secrets = append(Secrets, Secret{
+			ID:        id,
+			Content:   value,
+			recipient: k.AgeRecipient,
+			profile:   k.Profile,
+		})
+		helpers.Progress(helpers.ProgressEvent{Event: helpers.EventSecretPulled, Profile: k.Profile, Secret: id})
+	}
+	return Secrets, nil
+}
+
+// K8sManager ListAll lists every key of the secrets in the namespace
+func (k *K8sManager) ListAll() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helpers.ListTimeout)
+	defer cancel()
+
+	ns, err := k.namespace(ctx)
+	if err != nil {
+		return []string{}, err
+	}
+	objects, err := k.kubectl().List(ctx, ns)
+	if err != nil {
+		return []string{}, err
+	}
+	var ids []string
+	for _, s := range objects {
+		if !k.listed(s.Type()) {
+			continue
+		}
+		for _, key := range s.Keys() {
+			ids = append(ids, ns+"/"+s.Name()+"/"+key)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// K8sManager Rollback
+func (k *K8sManager) Rollback() error {
+	return Errorf(KindUnsupported, "k8s manager %s does not keep versions to rollback to", k.Profile)
+}
+
+// K8sManager Set writes the local secrets into their Kubernetes secrets, each secret is replaced once with
+// all of its changed keys and fails with a conflict when it changed since it was read
+func (k *K8sManager) Set(secretsPath string, createPrompt bool) error {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	sID, err := helpers.GetSecretNames(secretsPath)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("push", k.Profile, sID); err != nil {
+		return err
+	}
+	refs := map[string][]k8sRef{}
+	var objects []string
+	for _, id := range sID {
+		ref, err := parseK8sRef(id)
+		if err != nil {
+			return err
+		}
+		if _, ok := refs[ref.object()]; !ok {
+			objects = append(objects, ref.object())
+		}
+		refs[ref.object()] = append(refs[ref.object()], ref)
+	}
+
+	var pushed, done []string
+	defer func() {
+		trackRecent(k.Profile, pushed...)
+		notify("push", k.Profile, pushed)
+	}()
+	defer helpers.StartWork()()
+	for i, object := range objects {
+		if err = interrupted("push", done, remainingIDs(refs, objects[i:])); err != nil {
+			return err
+		}
+		changed, err := k.push(ctx, secretsPath, refs[object], createPrompt)
+		if err != nil {
+			if stopErr := interrupted("push", done, remainingIDs(refs, objects[i:])); stopErr != nil {
+				return stopErr
+			}
+			return err
+		}
+		pushed = append(pushed, changed...)
+		done = append(done, remainingIDs(refs, objects[i:i+1])...)
+	}
+	return nil
+}
+
+// remainingIDs returns the secret IDs of the objects
+func remainingIDs(refs map[string][]k8sRef, objects []string) []string {
+	var ids []string
+	for _, object := range objects {
+		for _, ref := range refs[object] {
+			ids = append(ids, object+"/"+ref.key)
+		}
+	}
+	return ids
+}
+
+// push writes the keys of one secret, it returns the IDs of the keys that changed
+func (k *K8sManager) push(ctx context.Context, secretsPath string, refs []k8sRef, createPrompt bool) ([]string, error) {
+	kubectl := k.kubectl()
+	first := refs[0]
+	getCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+	s, err := kubectl.Get(getCtx, first.namespace, first.name)
+	cancelGet()
+	if err != nil {
+		return nil, err
+	}
+	create := s == nil
+	if create {
+		if !createPrompt && !helpers.PromptYesNo(fmt.Sprintf("%s was not found, would you like to create this secret?", first.object())) {
+			helpers.Infof("creation of %s %s\n", first.object(), color.CyanString("skipped"))
+			return nil, nil
+		}
+		s = k8s.NewSecret(first.namespace, first.name)
+	}
+
+	var changed []string
+	for _, ref := range refs {
+		id := ref.object() + "/" + ref.key
+		secretUpdate, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", secretsPath, id))
+		if err != nil {
+			return nil, err
+		}
+		value := string(secretUpdate)
+		helpers.AddRedaction(value)
+		current, ok, err := s.Value(ref.key)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", id, err)
+		}
+		if ok && current == value {
+			helpers.Infof("%s %s\n", id, color.CyanString("skipped"))
+			progressPush(k.Profile, id, nil, false)
+			continue
+		}
+		s.SetValue(ref.key, value)
+		changed = append(changed, id)
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	pushCtx, cancelPush := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancelPush()
+	if create {
+		err = kubectl.Create(pushCtx, s)
+	} else {
+		err = kubectl.Replace(pushCtx, s)
+	}
+	for _, id := range changed {
+		progressPush(k.Profile, id, err, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range changed {
+		if create {
+			helpers.Infof("%s %s\n", id, color.MagentaString("created"))
+		} else {
+			helpers.Infof("%s %s\n", id, color.YellowString("updated"))
+		}
+	}
+	return changed, nil
+}
@@ -2,17 +2,32 @@ package secretsmanager
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"sync"
 
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/fatih/color"
 	"github.com/jacbart/jaws/internal/aws"
+	"github.com/jacbart/jaws/utils/helpers"
 	"github.com/ktr0731/go-fuzzyfinder"
 )
 
 func (a *AWSManager) FuzzyFind(ctx context.Context) ([]string, error) {
 	var selectedIDs []string
-	var allIDs []string
+	picks, err := LoadPicks()
+	if err != nil {
+		helpers.Verbosef(helpers.VerbosityVerbose, "loading favorite and recent secrets: %v\n", err)
+	}
+	// favorites and recent secrets are listed first, before the listing from aws comes in
+	allIDs := picks.top(a.Profile)
+	picked := map[string]bool{}
+	for _, id := range allIDs {
+		picked[id] = true
+	}
 	go func(a *AWSManager, list *[]string) {
+		defer helpers.HandlePanic()
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
@@ -22,22 +37,26 @@ func (a *AWSManager) FuzzyFind(ctx context.Context) ([]string, error) {
 		}
 
 		var l int
-		listSecretsOutput, err := aws.GetSecretsList(ctx, awsClient, nil)
+		listSecretsOutput, err := aws.GetSecretsList(ctx, awsClient, nil, nil)
 		if err != nil {
 			log.Fatalln(err)
 		}
 		l = len(listSecretsOutput.SecretList)
 		for i := 0; i < l; i++ {
-			*list = append(*list, *listSecretsOutput.SecretList[i].Name)
+			if name := *listSecretsOutput.SecretList[i].Name; !picked[name] {
+				*list = append(*list, name)
+			}
 		}
 		for listSecretsOutput.NextToken != nil {
-			listSecretsOutput, err = aws.GetSecretsList(ctx, awsClient, listSecretsOutput.NextToken)
+			listSecretsOutput, err = aws.GetSecretsList(ctx, awsClient, listSecretsOutput.NextToken, nil)
 			if err != nil {
 				log.Fatalln(err)
 			}
 			l = len(listSecretsOutput.SecretList)
 			for i := 0; i < l; i++ {
-				*list = append(*list, *listSecretsOutput.SecretList[i].Name)
+				if name := *listSecretsOutput.SecretList[i].Name; !picked[name] {
+					*list = append(*list, name)
+				}
 			}
 		}
 	}(a, &allIDs)
@@ -46,7 +65,7 @@ func (a *AWSManager) FuzzyFind(ctx context.Context) ([]string, error) {
 	l := rw.RLocker()
 
 	idxs, _ := fuzzyfinder.FindMulti(&allIDs, func(i int) string {
-		return allIDs[i]
+		return picks.label(allIDs[i], a.Profile)
 	}, fuzzyfinder.WithHotReloadLock(l))
 	for _, idx := range idxs {
 		selectedIDs = append(selectedIDs, allIDs[idx])
@@ -56,33 +75,173 @@ func (a *AWSManager) FuzzyFind(ctx context.Context) ([]string, error) {
 
 // AWSManager ListAll
 func (a *AWSManager) ListAll() ([]string, error) {
+	infos, err := a.ListQuery(Query{})
+	if err != nil {
+		return []string{}, err
+	}
+	return secretIDs(infos), nil
+}
+
+// AWSManager ListInfo
+func (a *AWSManager) ListInfo() ([]SecretInfo, error) {
+	return a.ListQuery(Query{})
+}
+
+// AWSManager ListQuery lists the secrets matching the query, prefixes and IDs are sent to aws as name filters
+// and tags as tag key and value filters, a regex is matched on the listed secrets
+func (a *AWSManager) ListQuery(q Query) ([]SecretInfo, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	var list []string
 
 	awsClient, err := LoadAWSClient(a, ctx)
 	if err != nil {
-		return []string{}, err
+		return nil, err
 	}
 
-	var l int
-	listSecretsOutput, err := aws.GetSecretsList(ctx, awsClient, nil)
-	if err != nil {
-		return []string{}, err
+	seen := map[string]bool{}
+	var infos []SecretInfo
+	tagFilters := aws.TagFilters(q.Tags)
+	for _, filters := range aws.NameFilters(q.namePrefixes()) {
+		filters = append(filters, tagFilters...)
+		var nextToken *string
+		for {
+			listSecretsOutput, err := aws.GetSecretsList(ctx, awsClient, nextToken, filters)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range listSecretsOutput.SecretList {
+				info := entryInfo(e)
+				if seen[info.ID] || !q.Match(info) {
+					continue
+				}
+				seen[info.ID] = true
+				infos = append(infos, info)
+				if q.MaxResults > 0 && len(infos) >= q.MaxResults {
+					return infos, nil
+				}
+			}
+			if listSecretsOutput.NextToken == nil {
+				break
+			}
+			helpers.Verbosef(helpers.VerbosityDebug, "listed %d secrets from %s, fetching next page\n", len(infos), a.Profile)
+			nextToken = listSecretsOutput.NextToken
+		}
+	}
+	return infos, nil
+}
+
+// entryInfo converts a listed aws secret into its SecretInfo
+func entryInfo(e types.SecretListEntry) SecretInfo {
+	info := SecretInfo{
+		ID:   *e.Name,
+		Tags: map[string]string{},
 	}
-	l = len(listSecretsOutput.SecretList)
-	for i := 0; i < l; i++ {
-		list = append(list, *listSecretsOutput.SecretList[i].Name)
+	if e.LastChangedDate != nil {
+		info.Modified = *e.LastChangedDate
 	}
-	for listSecretsOutput.NextToken != nil {
-		listSecretsOutput, err = aws.GetSecretsList(ctx, awsClient, listSecretsOutput.NextToken)
-		if err != nil {
-			return []string{}, err
+	for version, stages := range e.SecretVersionsToStages {
+		if helpers.Contains(stages, "AWSCURRENT") {
+			info.Version = version
 		}
-		l = len(listSecretsOutput.SecretList)
-		for i := 0; i < l; i++ {
-			list = append(list, *listSecretsOutput.SecretList[i].Name)
+	}
+	for _, t := range e.Tags {
+		if t.Key != nil && t.Value != nil {
+			info.Tags[*t.Key] = *t.Value
 		}
 	}
-	return list, nil
+	return info
+}
+
+// leafManagers returns the managers that are not chains, chains list the secrets of their first profile
+// which would show up twice
+func leafManagers(managers []Manager) []Manager {
+	var leaves []Manager
+	for _, m := range managers {
+		if _, isChain := m.(*ChainManager); !isChain {
+			leaves = append(leaves, m)
+		}
+	}
+	if len(leaves) == 0 {
+		return managers
+	}
+	return leaves
+}
+
+// listProfiles lists the secrets of every manager at the same time, the lists are returned in the order of the
+// managers. A profile that fails to list is skipped with a warning.
+func listProfiles(managers []Manager) [][]string {
+	lists := make([][]string, len(managers))
+	var wg sync.WaitGroup
+	for i, m := range managers {
+		wg.Add(1)
+		go func(i int, m Manager) {
+			defer wg.Done()
+			defer helpers.HandlePanic()
+			ids, err := m.ListAll()
+			if err != nil {
+				color.Yellow("skipping profile %s: %v", m.ProfileName(), err)
+				return
+			}
+			lists[i] = ids
+		}(i, m)
+	}
+	wg.Wait()
+	return lists
+}
+
+// NarrowFind lists the secrets of the given profiles, keeps the ones fuzzy matching partial and opens them in
+// the fuzzy finder. Selections are returned as profile:secret references.
+func NarrowFind(managers []Manager, partial string) ([]string, error) {
+	return findProfiles(leafManagers(managers), partial, fmt.Sprintf("secrets matching %s", partial))
+}
+
+// FindAll lists the secrets of every profile at the same time and opens them in one fuzzy finder with a
+// profile column. Selections are returned as profile:secret references.
+func FindAll(managers []Manager) ([]string, error) {
+	return findProfiles(leafManagers(managers), "", "secrets of every profile")
+}
+
+// findProfiles opens the secrets of the profiles fuzzy matching partial in the fuzzy finder, labeled with their
+// profile when there is more than one
+func findProfiles(managers []Manager, partial string, header string) ([]string, error) {
+	width := 0
+	for _, m := range managers {
+		if len(m.ProfileName()) > width {
+			width = len(m.ProfileName())
+		}
+	}
+	var refs []string
+	var labels []string
+	for i, ids := range listProfiles(managers) {
+		profile := managers[i].ProfileName()
+		for _, id := range ids {
+			if partial != "" && !helpers.FuzzyMatch(partial, id) {
+				continue
+			}
+			refs = append(refs, PinRef(profile, id))
+			if len(managers) > 1 {
+				labels = append(labels, fmt.Sprintf("%-*s  %s", width, profile, id))
+			} else {
+				labels = append(labels, id)
+			}
+		}
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	idxs, err := fuzzyfinder.FindMulti(labels, func(i int) string {
+		return labels[i]
+	}, fuzzyfinder.WithHeader(header))
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var selected []string
+	for _, idx := range idxs {
+		selected = append(selected, refs[idx])
+	}
+	return selected, nil
 }
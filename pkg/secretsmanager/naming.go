@@ -0,0 +1,99 @@
+package secretsmanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// NamingHCL holds the naming convention secrets are checked against before they are created or set
+type NamingHCL struct {
+	Pattern  string   `hcl:"pattern,optional"`
+	Segments []string `hcl:"segments,optional"`
+}
+
+// Validate checks the secret ID against the naming convention, a nil convention accepts any name
+func (n *NamingHCL) Validate(secretID string) error {
+	if n == nil {
+		return nil
+	}
+	if len(n.Segments) != 0 {
+		parts := strings.Split(secretID, "/")
+		if len(parts) != len(n.Segments) {
+			return Errorf(KindValidation, "%s has %d segments, expected %d (%s)", secretID, len(parts), len(n.Segments), strings.Join(n.Segments, "/"))
+		}
+		for i, part := range parts {
+			if part == "" {
+				return Errorf(KindValidation, "%s has an empty %s segment", secretID, n.Segments[i])
+			}
+		}
+	}
+	if n.Pattern != "" {
+		re, err := regexp.Compile(n.Pattern)
+		if err != nil {
+			return Errorf(KindValidation, "naming pattern %s: %w", n.Pattern, err)
+		}
+		if !re.MatchString(secretID) {
+			return Errorf(KindValidation, "%s does not match the naming pattern %s", secretID, n.Pattern)
+		}
+	}
+	return nil
+}
+
+// PromptValidName validates the secret ID and asks for a corrected name until one passes,
+// an empty answer or a non interactive session returns the validation error
+func (n *NamingHCL) PromptValidName(secretID string) (string, error) {
+	for {
+		err := n.Validate(secretID)
+		if err == nil {
+			return secretID, nil
+		}
+		if !helpers.IsInteractive() {
+			return "", err
+		}
+		color.Yellow(err.Error())
+		fmt.Fprintf(helpers.InfoOutput, "enter a corrected name for %s (leave empty to abort): ", secretID)
+		var answer string
+		fmt.Scanln(&answer)
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			return "", err
+		}
+		secretID = answer
+	}
+}
+
+// ValidateLocalNames checks every secret in the secrets path against the naming convention,
+// files are moved when a corrected name is given
+func (n *NamingHCL) ValidateLocalNames(secretsPath string) error {
+	if n == nil {
+		return nil
+	}
+	sID, err := helpers.GetSecretNames(secretsPath)
+	if err != nil {
+		return err
+	}
+	for _, id := range sID {
+		name, err := n.PromptValidName(id)
+		if err != nil {
+			return err
+		}
+		if name == id {
+			continue
+		}
+		newPath := fmt.Sprintf("%s/%s", secretsPath, name)
+		if err = os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+			return err
+		}
+		if err = os.Rename(fmt.Sprintf("%s/%s", secretsPath, id), newPath); err != nil {
+			return err
+		}
+		helpers.Infof("%s %s %s\n", id, color.CyanString("renamed to"), name)
+	}
+	return nil
+}
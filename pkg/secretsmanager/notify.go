@@ -0,0 +1,147 @@
+package secretsmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// NotifyEvents are the operations a notify block can be fired on
+var NotifyEvents = []string{"push", "delete", "restore", "rollback", "tag", "destroy"}
+
+// NotifyHCL posts to a webhook after the listed operations changed secrets, the payload only holds
+// secret names, never their values
+type NotifyHCL struct {
+	On       []string `hcl:"on"`
+	Webhook  string   `hcl:"webhook"`
+	Template string   `hcl:"template,optional"`
+}
+
+// NotifyEvent is the data passed to a notify template
+type NotifyEvent struct {
+	Event   string
+	Profile string
+	Secrets []string
+	Ticket  string
+	User    string
+	Host    string
+	Time    time.Time
+}
+
+// Message is the default one line summary of the event
+func (e NotifyEvent) Message() string {
+	msg := fmt.Sprintf("jaws %s by %s@%s in profile %s: %s", e.Event, e.User, e.Host, e.Profile, strings.Join(e.Secrets, ", "))
+	if e.Ticket != "" {
+		msg += fmt.Sprintf(" (%s)", e.Ticket)
+	}
+	return msg
+}
+
+var notifyHooks []notifyHook
+
+type notifyHook struct {
+	on       []string
+	webhook  string
+	template *template.Template
+}
+
+// SetNotify validates the notify blocks and enables them for every manager
+func SetNotify(hooks []NotifyHCL) error {
+	notifyHooks = nil
+	for _, h := range hooks {
+		for _, event := range h.On {
+			if !helpers.Contains(NotifyEvents, event) {
+				return Errorf(KindValidation, "notify: unknown event `%s`, available events are %s", event, strings.Join(NotifyEvents, ","))
+			}
+		}
+		hook := notifyHook{on: h.On, webhook: h.Webhook}
+		if h.Template != "" {
+			funcs := template.FuncMap{"json": jsonString}
+			for name, f := range helpers.TemplateFuncs {
+				funcs[name] = f
+			}
+			tmpl, err := template.New("notify").Funcs(funcs).Parse(h.Template)
+			if err != nil {
+				return Errorf(KindValidation, "notify template for %s: %w", h.Webhook, err)
+			}
+			hook.template = tmpl
+		}
+		notifyHooks = append(notifyHooks, hook)
+	}
+	return nil
+}
+
+// notify posts the event to every webhook listening for it, failures are printed as warnings and never fail the operation
+func notify(event string, profile string, secretIDs []string) {
+	if len(secretIDs) == 0 {
+		return
+	}
+	// the secret lists of a running agent no longer match the provider
+	agent.forget()
+	if len(notifyHooks) == 0 {
+		return
+	}
+	e := NotifyEvent{
+		Event:   event,
+		Profile: profile,
+		Secrets: secretIDs,
+		Ticket:  ticket,
+		User:    currentUser(),
+		Time:    time.Now(),
+	}
+	e.Host, _ = os.Hostname()
+	for _, h := range notifyHooks {
+		if !helpers.Contains(h.on, event) {
+			continue
+		}
+		if err := h.post(e); err != nil {
+			color.Yellow("notify %s: %v", h.webhook, err)
+		}
+	}
+}
+
+// post renders the payload, a slack style {"text": "..."} message unless a template is set
+func (h notifyHook) post(e NotifyEvent) error {
+	var body bytes.Buffer
+	if h.template != nil {
+		if err := h.template.Execute(&body, e); err != nil {
+			return err
+		}
+	} else if err := json.NewEncoder(&body).Encode(map[string]string{"text": e.Message()}); err != nil {
+		return err
+	}
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(h.webhook, "application/json", &body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	helpers.Verbosef(helpers.VerbosityDebug, "notified %s of %s\n", h.webhook, e.Event)
+	return nil
+}
+
+// jsonString quotes a value as a json string for use inside notify templates
+func jsonString(v interface{}) (string, error) {
+	b, err := json.Marshal(fmt.Sprint(v))
+	return string(b), err
+}
+
+// currentUser returns the login name of the user running jaws
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
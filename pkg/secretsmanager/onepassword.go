@@ -0,0 +1,259 @@
+package secretsmanager
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jacbart/jaws/internal/onepassword"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// OnePasswordManager reads item fields from 1Password through a Connect server or, with a service account
+// token, the op cli. Secret IDs are vault/item/field using the names shown in 1Password.
+type OnePasswordManager struct {
+	Profile             string
+	ConnectHost         string   `hcl:"connect_host,optional"`
+	ConnectToken        string   `hcl:"connect_token,optional"`
+	ServiceAccountToken string   `hcl:"service_account_token,optional"`
+	Op                  string   `hcl:"op,optional"`
+	Vaults              []string `hcl:"vaults,optional"`
+	AgeRecipient        string   `hcl:"age_recipient,optional"`
+	client              onepassword.Client
+}
+
+// loadClient returns a Connect client when a host is configured or OP_CONNECT_HOST is set, the op cli with
+// the service account token otherwise
+func (o *OnePasswordManager) loadClient() (onepassword.Client, error) {
+	if o.client != nil {
+		return o.client, nil
+	}
+	helpers.Verbosef(helpers.VerbosityDebug, "loading 1password client for profile %s\n", o.Profile)
+	if host := firstNonEmpty(o.ConnectHost, os.Getenv("OP_CONNECT_HOST")); host != "" {
+		token := firstNonEmpty(o.ConnectToken, os.Getenv("OP_CONNECT_TOKEN"))
+		if token == "" {
+			return nil, Errorf(KindAuth, "1password manager %s needs connect_token or OP_CONNECT_TOKEN", o.Profile)
+		}
+		helpers.AddRedaction(token)
+		o.client = &onepassword.Connect{Host: host, Token: token}
+		return o.client, nil
+	}
+	token := firstNonEmpty(o.ServiceAccountToken, os.Getenv("OP_SERVICE_ACCOUNT_TOKEN"))
+	if token == "" {
+		return nil, Errorf(KindAuth, "1password manager %s needs connect_host or a service_account_token", o.Profile)
+	}
+	helpers.AddRedaction(token)
+	o.client = &onepassword.CLI{Path: o.Op, Token: token}
+	return o.client, nil
+}
+
+// readOnly is returned for every change, the manager only pulls from 1Password
+func (o *OnePasswordManager) readOnly() error {
+	return Errorf(KindUnsupported, "1password manager %s is read only", o.Profile)
+}
+
+// vaults returns the vaults to list, the configured ones or every vault the client can read
+func (o *OnePasswordManager) vaults(ctx context.Context, client onepassword.Client) ([]onepassword.Vault, error) {
+	all, err := client.Vaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.Vaults) == 0 {
+		return all, nil
+	}
+	var vaults []onepassword.Vault
+	for _, v := range all {
+		if helpers.Contains(o.Vaults, v.Name) {
+			vaults = append(vaults, v)
+		}
+	}
+	return vaults, nil
+}
+
+// fieldIDs returns the secret IDs of the labeled fields of the item, names holding a / can not be addressed
+func fieldIDs(vault string, item *onepassword.Item) []string {
+	if strings.Contains(vault, "/") || strings.Contains(item.Title, "/") {
+		return nil
+	}
+	var ids []string
+	for _, f := range item.Fields {
+		if f.Label != "" && !strings.Contains(f.Label, "/") {
+			ids = append(ids, vault+"/"+item.Title+"/"+f.Label)
+		}
+	}
+	return ids
+}
+
+// OnePasswordManager Create
+func (o *OnePasswordManager) Create(args []string, secretsPath string, useEditor bool) error {
+	return createLocalSecret(args, secretsPath, useEditor)
+}
+
+// OnePasswordManager Delete
+func (o *OnePasswordManager) Delete(scheduleInDays int64) error {
+	return o.readOnly()
+}
+
+// OnePasswordManager DeleteCancel
+func (o *OnePasswordManager) DeleteCancel(args []string) error {
+	return o.readOnly()
+}
+
+// OnePasswordManager FuzzyFind
+func (o *OnePasswordManager) FuzzyFind(ctx context.Context) ([]string, error) {
+	allIDs, err := o.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	return findWithPicks(allIDs, o.Profile)
+}
+
+// OnePasswordManager Get reads the fields, each item is read once no matter how many of its fields are requested
+func (o *OnePasswordManager) Get(secretsIDList []string) ([]Secret, error) {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	client, err := o.loadClient()
+	if err != nil {
+		return []Secret{}, err
+	}
+	secretIDs := secretsIDList
+	if len(secretIDs) == 0 {
+		if secretIDs, err = o.FuzzyFind(ctx); err != nil {
+			return []Secret{}, err
+		}
+	}
+
+	var vaults []onepassword.Vault
+	itemLists := map[string][]onepassword.Item{}
+	items := map[string]*onepassword.Item{}
+	var Secrets []Secret
+	defer helpers.StartWork()()
+	for i, id := range secretIDs {
+		if err = interrupted("pull", secretIDs[:i], secretIDs[i:]); err != nil {
+			return Secrets, err
+		}
+		parts := strings.SplitN(id, "/", 3)
+		if len(parts) != 3 || strings.Contains(parts[2], "/") {
+			// only vault/item/field IDs can be found
+			continue
+		}
+		timeCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+		item, err := o.item(timeCtx, client, &vaults, itemLists, items, parts[0], parts[1])
+		cancelGet()
+		if err != nil {
+			if stopErr := interrupted("pull", secretIDs[:i], secretIDs[i:]); stopErr != nil {
+				return Secrets, stopErr
+			}
+			return []Secret{}, err
+		}
+		if item == nil {
+			continue
+		}
+		for _, f := range item.Fields {
+			if f.Label != parts[2] {
+				continue
+			}
+			helpers.AddRedaction(f.Value)
+			Secrets = append(Secrets, Secret{
+				ID:        id,
+				Content:   f.Value,
+				recipient: o.AgeRecipient,
+				profile:   o.Profile,
+			})
+			helpers.Progress(helpers.ProgressEvent{Event: helpers.EventSecretPulled, Profile: o.Profile, Secret: id})
+			break
+		}
+	}
+	return Secrets, nil
+}
+
+// item finds the item by vault name and title, vaults and items already listed or read are reused.
+// A vault or item that does not exist returns nil.
+func (o *OnePasswordManager) item(ctx context.Context, client onepassword.Client, vaults *[]onepassword.Vault,
+	itemLists map[string][]onepassword.Item, items map[string]*onepassword.Item, vaultName string, title string) (*onepassword.Item, error) {
+	key := vaultName + "/" + title
+	if item, ok := items[key]; ok {
+		return item, nil
+	}
+	if *vaults == nil {
+		all, err := o.vaults(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		*vaults = all
+	}
+	vaultID := ""
+	for _, v := range *vaults {
+		if v.Name == vaultName {
+			vaultID = v.ID
+			break
+		}
+	}
+	if vaultID == "" {
+		items[key] = nil
+		return nil, nil
+	}
+	list, listed := itemLists[vaultID]
+	if !listed {
+		var err error
+		if list, err = client.Items(ctx, vaultID); err != nil {
+			return nil, err
+		}
+		itemLists[vaultID] = list
+	}
+	for _, summary := range list {
+		if summary.Title == title {
+			item, err := client.Item(ctx, vaultID, summary.ID)
+			if err != nil {
+				return nil, err
+			}
+			items[key] = item
+			return item, nil
+		}
+	}
+	items[key] = nil
+	return nil, nil
+}
+
+// OnePasswordManager ListAll lists every labeled field of the items in the vaults
+func (o *OnePasswordManager) ListAll() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helpers.ListTimeout)
+	defer cancel()
+
+	client, err := o.loadClient()
+	if err != nil {
+		return []string{}, err
+	}
+	vaults, err := o.vaults(ctx, client)
+	if err != nil {
+		return []string{}, err
+	}
+	var ids []string
+	for _, v := range vaults {
+		list, err := client.Items(ctx, v.ID)
+		if err != nil {
+			return []string{}, err
+		}
+		for _, summary := range list {
+			item, err := client.Item(ctx, v.ID, summary.ID)
+			if err != nil {
+				return []string{}, err
+			}
+			ids = append(ids, fieldIDs(v.Name, item)...)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// OnePasswordManager Rollback
+func (o *OnePasswordManager) Rollback() error {
+	return o.readOnly()
+}
+
+// OnePasswordManager Set
+func (o *OnePasswordManager) Set(secretsPath string, createPrompt bool) error {
+	return o.readOnly()
+}
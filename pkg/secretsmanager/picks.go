@@ -0,0 +1,215 @@
+package secretsmanager
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/jacbart/jaws/utils/helpers"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+// maxRecent caps how many recently used secrets are kept for each profile
+const maxRecent = 10
+
+// Picks holds the favorite and recently pulled or pushed secrets of each profile, they are listed first in the fuzzy finder
+type Picks struct {
+	Favorites map[string][]string `json:"favorites,omitempty"`
+	Recent    map[string][]string `json:"recent,omitempty"`
+}
+
+// picksFile returns the path of the state file holding the favorite and recent secrets
+func picksFile() string {
+	return helpers.StatePath("picks.json")
+}
+
+// LoadPicks reads the favorite and recent secrets, a missing state file returns none
+func LoadPicks() (Picks, error) {
+	p := Picks{}
+	if err := loadState(picksFile(), &p); err != nil {
+		return Picks{}, err
+	}
+	if p.Favorites == nil {
+		p.Favorites = map[string][]string{}
+	}
+	if p.Recent == nil {
+		p.Recent = map[string][]string{}
+	}
+	return p, nil
+}
+
+// AddFavorites pins the secrets to the top of the fuzzy finder, references without a profile: prefix are added
+// to the default profile
+func AddFavorites(defaultProfile string, refs ...string) error {
+	p, err := LoadPicks()
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		profile, id := ParseSecretRef(ref)
+		if profile == "" {
+			profile = defaultProfile
+		}
+		if !helpers.Contains(p.Favorites[profile], id) {
+			p.Favorites[profile] = append(p.Favorites[profile], id)
+			sort.Strings(p.Favorites[profile])
+		}
+	}
+	return saveState(picksFile(), p)
+}
+
+// RemoveFavorites unpins the secrets, references without a profile: prefix are removed from the default profile
+func RemoveFavorites(defaultProfile string, refs ...string) error {
+	p, err := LoadPicks()
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		profile, id := ParseSecretRef(ref)
+		if profile == "" {
+			profile = defaultProfile
+		}
+		var kept []string
+		for _, fav := range p.Favorites[profile] {
+			if fav != id {
+				kept = append(kept, fav)
+			}
+		}
+		if len(kept) == 0 {
+			delete(p.Favorites, profile)
+		} else {
+			p.Favorites[profile] = kept
+		}
+	}
+	return saveState(picksFile(), p)
+}
+
+// FavoriteRefs returns every favorite secret pinned to its profile, sorted by profile
+func (p Picks) FavoriteRefs() []string {
+	profiles := make([]string, 0, len(p.Favorites))
+	for profile := range p.Favorites {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	var refs []string
+	for _, profile := range profiles {
+		for _, id := range p.Favorites[profile] {
+			refs = append(refs, PinRef(profile, id))
+		}
+	}
+	return refs
+}
+
+// trackRecent moves the secrets to the front of the recent secrets of the profile, failing to save them only
+// shows up in the verbose output since it should not fail the pull or push
+func trackRecent(profile string, secretIDs ...string) {
+	if len(secretIDs) == 0 {
+		return
+	}
+	p, err := LoadPicks()
+	if err == nil {
+		recent := append([]string{}, secretIDs...)
+		for _, id := range p.Recent[profile] {
+			if !helpers.Contains(recent, id) {
+				recent = append(recent, id)
+			}
+		}
+		if len(recent) > maxRecent {
+			recent = recent[:maxRecent]
+		}
+		p.Recent[profile] = recent
+		err = saveState(picksFile(), p)
+	}
+	if err != nil {
+		helpers.Verbosef(helpers.VerbosityVerbose, "saving recent secrets: %v\n", err)
+	}
+}
+
+// TrackRecent records the pulled secrets as recent secrets of the profiles they came from
+func TrackRecent(Secrets []Secret) {
+	var profiles []string
+	ids := map[string][]string{}
+	for _, s := range Secrets {
+		if s.profile == "" {
+			continue
+		}
+		if _, ok := ids[s.profile]; !ok {
+			profiles = append(profiles, s.profile)
+		}
+		ids[s.profile] = append(ids[s.profile], s.ID)
+	}
+	for _, profile := range profiles {
+		trackRecent(profile, ids[profile]...)
+	}
+}
+
+// top returns the favorites followed by the recent secrets of the profiles
+func (p Picks) top(profiles ...string) []string {
+	var ids []string
+	for _, picks := range []map[string][]string{p.Favorites, p.Recent} {
+		for _, profile := range profiles {
+			for _, id := range picks[profile] {
+				if !helpers.Contains(ids, id) {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// label returns how the secret is shown in the fuzzy finder, favorites and recent secrets are marked
+func (p Picks) label(id string, profiles ...string) string {
+	for _, profile := range profiles {
+		if helpers.Contains(p.Favorites[profile], id) {
+			return "[fav] " + id
+		}
+	}
+	for _, profile := range profiles {
+		if helpers.Contains(p.Recent[profile], id) {
+			return "[recent] " + id
+		}
+	}
+	return id
+}
+
+// withPicks moves the favorite and recent secrets of the profiles to the front of the list
+func (p Picks) withPicks(ids []string, profiles ...string) []string {
+	listed := map[string]bool{}
+	for _, id := range ids {
+		listed[id] = true
+	}
+	sorted := make([]string, 0, len(ids))
+	picked := map[string]bool{}
+	for _, id := range p.top(profiles...) {
+		if listed[id] {
+			picked[id] = true
+			sorted = append(sorted, id)
+		}
+	}
+	for _, id := range ids {
+		if !picked[id] {
+			sorted = append(sorted, id)
+		}
+	}
+	return sorted
+}
+
+// findWithPicks opens the fuzzy finder over the secrets with the favorites and recent secrets of the profiles first
+func findWithPicks(ids []string, profiles ...string) ([]string, error) {
+	p, err := LoadPicks()
+	if err != nil {
+		helpers.Verbosef(helpers.VerbosityVerbose, "loading favorite and recent secrets: %v\n", err)
+	}
+	ids = p.withPicks(ids, profiles...)
+	idxs, err := fuzzyfinder.FindMulti(ids, func(i int) string {
+		return p.label(ids[i], profiles...)
+	})
+	if err != nil && !errors.Is(err, fuzzyfinder.ErrAbort) {
+		return nil, err
+	}
+	var selected []string
+	for _, idx := range idxs {
+		selected = append(selected, ids[idx])
+	}
+	return selected, nil
+}
@@ -0,0 +1,79 @@
+package secretsmanager_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jacbart/jaws/pkg/secretsmanager"
+	"github.com/jacbart/jaws/pkg/secretsmanager/fake"
+)
+
+func TestGetPinnedBare(t *testing.T) {
+	newManagers := func() (*fake.Manager, *fake.Manager) {
+		return fake.New("default", map[string]string{"a": "default-a", "b": "default-b"}),
+			fake.New("local", map[string]string{"a": "local-a", "c": "local-c"})
+	}
+	contents := func(Secrets []secretsmanager.Secret) map[string]string {
+		got := map[string]string{}
+		for _, s := range Secrets {
+			got[s.ID] = s.Content
+		}
+		return got
+	}
+	methods := func(m *fake.Manager) []string {
+		var called []string
+		for _, c := range m.Calls() {
+			called = append(called, c.Method)
+		}
+		return called
+	}
+
+	t.Run("without profile order the default profile wins", func(t *testing.T) {
+		def, local := newManagers()
+		Secrets, err := secretsmanager.GetPinned([]secretsmanager.Manager{def, local}, def, []string{"a", "c"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]string{"a": "default-a"}; !reflect.DeepEqual(contents(Secrets), want) {
+			t.Errorf("GetPinned = %q, want %q", contents(Secrets), want)
+		}
+		// the other profile is listed to find duplicates but nothing is pulled from it
+		if got := methods(local); !reflect.DeepEqual(got, []string{"ListInfo"}) {
+			t.Errorf("calls to the other profile = %q", got)
+		}
+	})
+
+	t.Run("profile order picks the first profile holding each secret", func(t *testing.T) {
+		def, local := newManagers()
+		Secrets, err := secretsmanager.GetPinned([]secretsmanager.Manager{def, local}, def, []string{"a", "b", "c"}, []string{"local"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{"a": "local-a", "b": "default-b", "c": "local-c"}
+		if !reflect.DeepEqual(contents(Secrets), want) {
+			t.Errorf("GetPinned = %q, want %q", contents(Secrets), want)
+		}
+		// the profiles are listed and each secret is only pulled from the profile chosen
+		for _, c := range def.Calls() {
+			if c.Method == "Get" && !reflect.DeepEqual(c.Args, []string{"b"}) {
+				t.Errorf("default profile pulled %q, want only b", c.Args)
+			}
+		}
+	})
+
+	t.Run("an unreachable profile of the order is skipped", func(t *testing.T) {
+		def, local := newManagers()
+		local.Errors["ListInfo"] = errors.New("no credentials")
+		Secrets, err := secretsmanager.GetPinned([]secretsmanager.Manager{def, local}, def, []string{"a"}, []string{"local"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]string{"a": "default-a"}; !reflect.DeepEqual(contents(Secrets), want) {
+			t.Errorf("GetPinned = %q, want %q", contents(Secrets), want)
+		}
+		if got := methods(local); !reflect.DeepEqual(got, []string{"ListInfo"}) {
+			t.Errorf("calls to the unreachable profile = %q", got)
+		}
+	})
+}
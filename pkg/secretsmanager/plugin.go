@@ -0,0 +1,310 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/internal/plugin"
+	"github.com/jacbart/jaws/utils/helpers"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+// PluginManager hands every operation to a provider plugin, an executable speaking the protocol of the
+// internal/plugin package, so providers can be added without changing jaws
+type PluginManager struct {
+	Profile      string
+	Plugin       string            `hcl:"plugin"`
+	Config       map[string]string `hcl:"config,optional"`
+	AgeRecipient string            `hcl:"age_recipient,optional"`
+}
+
+func (p *PluginManager) plugin() *plugin.Plugin {
+	return &plugin.Plugin{Path: p.Plugin, Profile: p.Profile, Config: p.Config}
+}
+
+// PluginManager Create
+func (p *PluginManager) Create(args []string, secretsPath string, useEditor bool) error {
+	return createLocalSecret(args, secretsPath, useEditor)
+}
+
+// PluginManager Delete deletes the selected secrets, the plugin decides whether the days are used
+func (p *PluginManager) Delete(scheduleInDays int64) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sID, err := p.FuzzyFind(ctx)
+	if err != nil {
+		return err
+	}
+	if len(sID) == 0 {
+		return nil
+	}
+	if err = requireChange("delete", p.Profile, sID); err != nil {
+		return err
+	}
+
+	timeCtx, cancelDelete := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancelDelete()
+	if _, err = p.plugin().Call(timeCtx, plugin.OpDelete, plugin.Request{IDs: sID, Days: scheduleInDays}); err != nil {
+		return err
+	}
+	notify("delete", p.Profile, sID)
+	for _, id := range sID {
+		helpers.Infof("%s %s\n", id, color.RedString("deleted"))
+	}
+	if err = TrackDeletions(p.Profile, sID...); err != nil {
+		color.Yellow("secrets were deleted but could not be tracked for delete cancel: %v", err)
+	}
+	return nil
+}
+
+// PluginManager DeleteCancel restores the given secrets, without any the secrets jaws deleted are listed to select from
+func (p *PluginManager) DeleteCancel(args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	secretIDs := args
+	if len(secretIDs) == 0 {
+		d, err := LoadDeletions()
+		if err != nil {
+			return err
+		}
+		tracked := d[p.Profile]
+		idxs, err := fuzzyfinder.FindMulti(tracked, func(i int) string {
+			return tracked[i]
+		}, fuzzyfinder.WithHeader("select secrets to restore"))
+		if err != nil && !errors.Is(err, fuzzyfinder.ErrAbort) {
+			return err
+		}
+		for _, idx := range idxs {
+			secretIDs = append(secretIDs, tracked[idx])
+		}
+	}
+	if len(secretIDs) == 0 {
+		return nil
+	}
+	if err := requireChange("restore", p.Profile, secretIDs); err != nil {
+		return err
+	}
+
+	timeCtx, cancelRestore := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancelRestore()
+	if _, err := p.plugin().Call(timeCtx, plugin.OpRestore, plugin.Request{IDs: secretIDs}); err != nil {
+		return err
+	}
+	notify("restore", p.Profile, secretIDs)
+	for _, id := range secretIDs {
+		helpers.Infof("%s %s\n", id, color.GreenString("restored"))
+	}
+	return UntrackDeletions(p.Profile, secretIDs...)
+}
+
+// PluginManager FuzzyFind
+func (p *PluginManager) FuzzyFind(ctx context.Context) ([]string, error) {
+	allIDs, err := p.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	return findWithPicks(allIDs, p.Profile)
+}
+
+// PluginManager Get asks the plugin for every secret in one request, secrets it does not return are missing
+func (p *PluginManager) Get(secretsIDList []string) ([]Secret, error) {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	secretIDs := secretsIDList
+	if len(secretIDs) == 0 {
+		var err error
+		if secretIDs, err = p.FuzzyFind(ctx); err != nil {
+			return []Secret{}, err
+		}
+	}
+	if len(secretIDs) == 0 {
+		return []Secret{}, nil
+	}
+
+	defer helpers.StartWork()()
+	timeCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+	values, err := p.plugin().Call(timeCtx, plugin.OpGet, plugin.Request{IDs: secretIDs})
+	cancelGet()
+	if err != nil {
+		if stopErr := interrupted("pull", nil, secretIDs); stopErr != nil {
+			return []Secret{}, stopErr
+		}
+		return []Secret{}, err
+	}
+	var Secrets []Secret
+	for _, v := range values {
+		if !helpers.Contains(secretIDs, v.ID) {
+			continue
+		}
+		helpers.AddRedaction(v.Value)
+		Secrets = append(Secrets, Secret{
+			ID:        v.ID,
+			Content:   v.Value,
+			recipient: p.AgeRecipient,
+			profile:   p.Profile,
+		})
+		helpers.Progress(helpers.ProgressEvent{Event: helpers.EventSecretPulled, Profile: p.Profile, Secret: v.ID})
+	}
+	return Secrets, nil
+}
+
+// PluginManager ListAll
+func (p *PluginManager) ListAll() ([]string, error) {
+	infos, err := p.ListInfo()
+	if err != nil {
+		return []string{}, err
+	}
+	return secretIDs(infos), nil
+}
+
+// PluginManager ListInfo lists the secrets with whatever metadata the plugin returns
+func (p *PluginManager) ListInfo() ([]SecretInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helpers.ListTimeout)
+	defer cancel()
+
+	listed, err := p.plugin().Call(ctx, plugin.OpList, plugin.Request{})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]SecretInfo, 0, len(listed))
+	for _, s := range listed {
+		info := SecretInfo{ID: s.ID, Version: s.Version, Tags: s.Tags}
+		if s.Modified != nil {
+			info.Modified = *s.Modified
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// PluginManager Rollback
+func (p *PluginManager) Rollback() error {
+	return p.RollbackSteps(1)
+}
+
+// PluginManager RollbackSteps asks the plugin to roll the selected secrets back by the given number of versions
+func (p *PluginManager) RollbackSteps(steps int) error {
+	if steps < 1 {
+		return Errorf(KindValidation, "steps must be at least 1")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sID, err := p.FuzzyFind(ctx)
+	if err != nil {
+		return err
+	}
+	if len(sID) == 0 {
+		return nil
+	}
+	if err = requireChange("rollback", p.Profile, sID); err != nil {
+		return err
+	}
+
+	timeCtx, cancelRollback := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancelRollback()
+	if _, err = p.plugin().Call(timeCtx, plugin.OpRollback, plugin.Request{IDs: sID, Steps: steps}); err != nil {
+		return err
+	}
+	notify("rollback", p.Profile, sID)
+	for _, id := range sID {
+		helpers.Infof("%s %s\n", id, color.YellowString("rolled back"))
+	}
+	return nil
+}
+
+// PluginManager Set compares the local secrets with the ones the plugin returns and sends the changed ones
+// in one request
+func (p *PluginManager) Set(secretsPath string, createPrompt bool) error {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	sID, err := helpers.GetSecretNames(secretsPath)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("push", p.Profile, sID); err != nil {
+		return err
+	}
+	if len(sID) == 0 {
+		return nil
+	}
+
+	defer helpers.StartWork()()
+	timeCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+	current, err := p.plugin().Call(timeCtx, plugin.OpGet, plugin.Request{IDs: sID})
+	cancelGet()
+	if err != nil {
+		if stopErr := interrupted("push", nil, sID); stopErr != nil {
+			return stopErr
+		}
+		return err
+	}
+	stored := map[string]string{}
+	for _, s := range current {
+		stored[s.ID] = s.Value
+	}
+
+	var changed []plugin.Secret
+	var created []string
+	for _, id := range sID {
+		secretUpdate, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", secretsPath, id))
+		if err != nil {
+			return err
+		}
+		value := string(secretUpdate)
+		helpers.AddRedaction(value)
+		old, exists := stored[id]
+		switch {
+		case exists && old == value:
+			helpers.Infof("%s %s\n", id, color.CyanString("skipped"))
+			progressPush(p.Profile, id, nil, false)
+			continue
+		case !exists && !createPrompt && !helpers.PromptYesNo(fmt.Sprintf("%s was not found, would you like to create this secret?", id)):
+			helpers.Infof("creation of %s %s\n", id, color.CyanString("skipped"))
+			progressPush(p.Profile, id, nil, false)
+			continue
+		case !exists:
+			created = append(created, id)
+		}
+		changed = append(changed, plugin.Secret{ID: id, Value: value})
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	if err = interrupted("push", nil, sID); err != nil {
+		return err
+	}
+
+	pushCtx, cancelPush := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancelPush()
+	_, err = p.plugin().Call(pushCtx, plugin.OpSet, plugin.Request{Secrets: changed})
+	var pushed []string
+	for _, s := range changed {
+		progressPush(p.Profile, s.ID, err, err == nil)
+		if err != nil {
+			continue
+		}
+		pushed = append(pushed, s.ID)
+		if helpers.Contains(created, s.ID) {
+			helpers.Infof("%s %s\n", s.ID, color.MagentaString("created"))
+		} else {
+			helpers.Infof("%s %s\n", s.ID, color.YellowString("updated"))
+		}
+	}
+	trackRecent(p.Profile, pushed...)
+	notify("push", p.Profile, pushed)
+	if err != nil {
+		if stopErr := interrupted("push", nil, sID); stopErr != nil {
+			return stopErr
+		}
+		return err
+	}
+	return nil
+}
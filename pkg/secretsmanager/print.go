@@ -2,12 +2,38 @@ package secretsmanager
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
+	"github.com/jacbart/jaws/internal/aws"
+	"github.com/jacbart/jaws/utils/helpers"
 )
 
+// ListColumns are the columns PrintSecretInfo can render
+var ListColumns = []string{"name", "modified", "version", "changed-by", "tags"}
+
+// SecretInfo holds the metadata of a secret used when listing
+type SecretInfo struct {
+	ID       string
+	Modified time.Time
+	Version  string
+	Tags     map[string]string
+}
+
+// CleanPrintSecrets prints the value of each secret, binary secrets are written as is without a newline
 func CleanPrintSecrets(Secrets []Secret) {
 	for _, s := range Secrets {
+		if s.IsBinary() {
+			os.Stdout.Write(s.Binary)
+			continue
+		}
 		fmt.Println(s.Content)
 	}
 }
@@ -15,6 +41,218 @@ func CleanPrintSecrets(Secrets []Secret) {
 func FormatPrintSecret(Secrets []Secret) {
 	for _, s := range Secrets {
 		fmt.Printf("Secret ID: %s\n", color.MagentaString(s.ID))
-		color.HiGreen(s.Content)
+		if s.IsBinary() {
+			fmt.Println(color.CyanString("binary, %d bytes", len(s.Binary)))
+			continue
+		}
+		fmt.Println(color.HiGreenString(s.Content))
+	}
+}
+
+// EnvFormats are the formats PrintEnvFormat prints secrets in
+var EnvFormats = []string{"dotenv", "toml", "shell", "export"}
+
+// tomlBareKey matches the keys toml allows without quotes
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// shellName matches the names a POSIX shell accepts for variables
+var shellName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// PrintEnvFormat prints the secrets as variables named by EnvKey of their ID, sorted by name. dotenv prints
+// KEY=value lines, toml prints KEY = "value" pairs and shell (or export) prints export KEY='value' lines to source.
+func PrintEnvFormat(w io.Writer, Secrets []Secret, format string) error {
+	if !helpers.Contains(EnvFormats, format) {
+		return Errorf(KindValidation, "unknown format %s, use one of %s", format, strings.Join(EnvFormats, ","))
+	}
+	vars, err := EnvMap(Secrets, nil)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		value := vars[key]
+		switch format {
+		case "dotenv":
+			b.WriteString(helpers.FormatDotEnvLine(key, value) + "\n")
+		case "toml":
+			if !utf8.ValidString(value) {
+				return Errorf(KindValidation, "%s is not valid UTF-8 and can not be written as toml", key)
+			}
+			if !tomlBareKey.MatchString(key) {
+				key = tomlString(key)
+			}
+			b.WriteString(key + " = " + tomlString(value) + "\n")
+		case "shell", "export":
+			if !shellName.MatchString(key) {
+				return Errorf(KindValidation, "%s is not a valid shell variable name", key)
+			}
+			b.WriteString("export " + key + "=" + helpers.ShellQuote(value) + "\n")
+		}
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// tomlString quotes s as a toml basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// heredocName matches the delimiters HeredocPrintSecrets accepts
+var heredocName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// HeredocPrintSecrets prints the secrets like CleanPrintSecrets wrapped in a quoted here-doc, ready to paste into
+// a shell script. A secret with a line equal to the delimiter would end the here-doc early and is refused.
+func HeredocPrintSecrets(Secrets []Secret, name string) error {
+	if !heredocName.MatchString(name) {
+		return Errorf(KindValidation, "here-doc name %q must be letters, digits and _", name)
+	}
+	for _, s := range Secrets {
+		if s.IsBinary() {
+			return Errorf(KindValidation, "%s is binary and can not be printed in a here-doc", s.ID)
+		}
+		for _, line := range strings.Split(s.Content, "\n") {
+			if line == name {
+				return Errorf(KindValidation, "%s has a line equal to %s, choose another here-doc name", s.ID, name)
+			}
+		}
+	}
+	fmt.Printf("cat <<'%s'\n", name)
+	CleanPrintSecrets(Secrets)
+	fmt.Println(name)
+	return nil
+}
+
+// ListInfo lists the secrets of the manager with metadata when the manager supports it, otherwise only names are filled in
+func ListInfo(m Manager) ([]SecretInfo, error) {
+	if d, ok := m.(Describer); ok {
+		return d.ListInfo()
+	}
+	list, err := m.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]SecretInfo, 0, len(list))
+	for _, id := range list {
+		infos = append(infos, SecretInfo{ID: id})
+	}
+	return infos, nil
+}
+
+// PrintSecretInfo writes the selected columns as an aligned table, or as tab separated values when output is tsv.
+// sortBy names a column to sort on, prefix it with - for descending order.
+func PrintSecretInfo(w io.Writer, infos []SecretInfo, columns []string, sortBy string, output string) error {
+	for _, c := range columns {
+		if !helpers.Contains(ListColumns, c) {
+			return Errorf(KindValidation, "unknown column `%s`, available columns are %s", c, strings.Join(ListColumns, ","))
+		}
+	}
+	if sortBy != "" {
+		desc := strings.HasPrefix(sortBy, "-")
+		column := strings.TrimPrefix(sortBy, "-")
+		if !helpers.Contains(ListColumns, column) {
+			return Errorf(KindValidation, "unknown sort column `%s`, available columns are %s", column, strings.Join(ListColumns, ","))
+		}
+		sort.SliceStable(infos, func(i, j int) bool {
+			var less bool
+			if column == "modified" {
+				less = infos[i].Modified.Before(infos[j].Modified)
+			} else {
+				less = infos[i].column(column) < infos[j].column(column)
+			}
+			if desc {
+				return !less
+			}
+			return less
+		})
+	}
+
+	switch output {
+	case "tsv":
+		for _, info := range infos {
+			fields := make([]string, 0, len(columns))
+			for _, c := range columns {
+				fields = append(fields, info.column(c))
+			}
+			fmt.Fprintln(w, strings.Join(fields, "\t"))
+		}
+		return nil
+	case "table", "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if len(columns) > 1 {
+			fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		}
+		for _, info := range infos {
+			fields := make([]string, 0, len(columns))
+			for _, c := range columns {
+				fields = append(fields, info.column(c))
+			}
+			fmt.Fprintln(tw, strings.Join(fields, "\t"))
+		}
+		return tw.Flush()
+	default:
+		return Errorf(KindValidation, "unknown output `%s`, use table or tsv", output)
+	}
+}
+
+// column returns the printable value of the named column
+func (s SecretInfo) column(name string) string {
+	switch name {
+	case "name":
+		return s.ID
+	case "modified":
+		if s.Modified.IsZero() {
+			return "-"
+		}
+		return s.Modified.Local().Format(time.RFC3339)
+	case "version":
+		if s.Version == "" {
+			return "-"
+		}
+		return s.Version
+	case "changed-by":
+		if by, ok := s.Tags[aws.ChangedByTag]; ok {
+			return by
+		}
+		return "-"
+	case "tags":
+		if len(s.Tags) == 0 {
+			return "-"
+		}
+		tags := make([]string, 0, len(s.Tags))
+		for k, v := range s.Tags {
+			tags = append(tags, fmt.Sprintf("%s=%s", k, v))
+		}
+		sort.Strings(tags)
+		return strings.Join(tags, ",")
 	}
+	return ""
 }
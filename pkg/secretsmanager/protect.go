@@ -0,0 +1,71 @@
+package secretsmanager
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+var (
+	protectedProfiles = map[string]bool{}
+	// confirmedProfiles are the protected profiles confirmed during this run, by --confirm or by typing the name
+	confirmedProfiles = map[string]bool{}
+)
+
+// ProtectedProfiles returns the sorted profiles of the manager blocks setting protected = true
+func (c Config) ProtectedProfiles() []string {
+	var profiles []string
+	for _, m := range c.Managers {
+		if m.Protected {
+			profiles = append(profiles, m.Profile)
+		}
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// SetProtected sets the protected profiles and the profiles confirmed for this run with --confirm, a confirmed
+// profile has to be one of the protected ones
+func SetProtected(profiles []string, confirmed []string) error {
+	protectedProfiles = map[string]bool{}
+	confirmedProfiles = map[string]bool{}
+	for _, p := range profiles {
+		protectedProfiles[p] = true
+	}
+	for _, p := range confirmed {
+		if !protectedProfiles[p] {
+			helpers.PrintSuggestions(p, helpers.Suggest(p, profiles))
+			return Errorf(KindValidation, "--confirm %s: profile %s is not protected", p, p)
+		}
+		confirmedProfiles[p] = true
+	}
+	return nil
+}
+
+// requireProtected asks for the profile name before the event changes secrets of a protected profile, the
+// profile is asked for once per run. Runs without a terminal have to pass --confirm PROFILE.
+func requireProtected(event string, profile string) error {
+	if !protectedProfiles[profile] || confirmedProfiles[profile] {
+		return nil
+	}
+	if !helpers.IsInteractive() {
+		return Errorf(KindValidation, "profile %s is protected, pass --confirm %s to %s", profile, profile, event)
+	}
+	typed := helpers.PromptText(fmt.Sprintf("profile %s is protected, type the profile name to %s:", color.RedString(profile), event))
+	if typed != profile {
+		return Errorf(KindValidation, "%s canceled, %q does not match profile %s", event, typed, profile)
+	}
+	confirmedProfiles[profile] = true
+	return nil
+}
+
+// requireChange runs the checks every change to secrets goes through, the protected profile confirmation and
+// the ticket rule
+func requireChange(event string, profile string, secretIDs []string) error {
+	if err := requireProtected(event, profile); err != nil {
+		return err
+	}
+	return requireTicket(event, secretIDs)
+}
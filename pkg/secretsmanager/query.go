@@ -0,0 +1,103 @@
+package secretsmanager
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Query narrows a listing of secrets, empty fields match every secret
+type Query struct {
+	// Prefixes matches secrets starting with any of the prefixes
+	Prefixes []string
+	// IDs matches these exact secrets
+	IDs []string
+	// Tags matches secrets having every one of these tags
+	Tags map[string]string
+	// Regex matches secrets whose ID matches the expression, it is checked on top of the prefixes and IDs
+	Regex *regexp.Regexp
+	// MaxResults stops the listing once this many secrets matched
+	MaxResults int
+}
+
+// Match reports whether the secret matches the query, a secret matches when it has every tag of the query,
+// matches the regex and has one of the prefixes or is one of the IDs
+func (q Query) Match(info SecretInfo) bool {
+	if q.Regex != nil && !q.Regex.MatchString(info.ID) {
+		return false
+	}
+	for k, v := range q.Tags {
+		if tag, ok := info.Tags[k]; !ok || tag != v {
+			return false
+		}
+	}
+	if len(q.Prefixes) == 0 && len(q.IDs) == 0 {
+		return true
+	}
+	for _, id := range q.IDs {
+		if id == info.ID {
+			return true
+		}
+	}
+	for _, prefix := range q.Prefixes {
+		if strings.HasPrefix(info.ID, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListQuery lists the secrets of the manager matching the query, managers implementing Querier filter on the
+// provider side and the others are listed in full and filtered here. With a jaws agent running the agent
+// filters its cached list instead.
+func ListQuery(m Manager, q Query) ([]SecretInfo, error) {
+	if infos, served, err := agent.listQuery(m.ProfileName(), q); served {
+		return infos, err
+	}
+	if querier, ok := m.(Querier); ok {
+		return querier.ListQuery(q)
+	}
+	infos, err := ListInfo(m)
+	if err != nil {
+		return nil, err
+	}
+	var matched []SecretInfo
+	for _, info := range infos {
+		if !q.Match(info) {
+			continue
+		}
+		matched = append(matched, info)
+		if q.MaxResults > 0 && len(matched) >= q.MaxResults {
+			break
+		}
+	}
+	return matched, nil
+}
+
+// namePrefixes returns the prefixes and IDs to filter the listing by on the provider side, without any the
+// literal start of a regex anchored with ^ is used
+func (q Query) namePrefixes() []string {
+	names := append(append([]string{}, q.Prefixes...), q.IDs...)
+	if len(names) != 0 || q.Regex == nil || !strings.HasPrefix(q.Regex.String(), "^") {
+		return names
+	}
+	if prefix, _ := q.Regex.LiteralPrefix(); prefix != "" {
+		return []string{prefix}
+	}
+	return nil
+}
+
+// globPrefix returns the literal start of a glob pattern, up to the first wildcard
+func globPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, "*?["); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
+func secretIDs(infos []SecretInfo) []string {
+	ids := make([]string, 0, len(infos))
+	for _, info := range infos {
+		ids = append(ids, info.ID)
+	}
+	return ids
+}
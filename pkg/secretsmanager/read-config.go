@@ -70,8 +70,8 @@ func (c *JawsConfig) ReadInConfig() (GeneralHCL, []Manager, error) {
 	parser := hclparse.NewParser()
 	srcHCL, diag := parser.ParseHCL(src, c.CurrentConfig)
 	if diag.HasErrors() {
-		return *nilGeneral, nil, fmt.Errorf(
-			"error in ReadConfig parsing HCL: %w", diag,
+		return *nilGeneral, nil, Errorf(
+			KindValidation, "error in ReadConfig parsing HCL: %w", diag,
 		)
 	}
 
@@ -104,10 +104,93 @@ func (c *JawsConfig) ReadInConfig() (GeneralHCL, []Manager, error) {
 				}
 			}
 			managers = append(managers, aws)
+		case "env":
+			env := &EnvManager{Profile: m.Profile}
+			if m.Auth != nil {
+				if diag := gohcl.DecodeBody(m.Auth, evalContext, env); diag.HasErrors() {
+					return *nilGeneral, nil, &DecodeConfigFailed{File: c.CurrentConfig}
+				}
+			}
+			managers = append(managers, env)
+		case "vault":
+			vault := &VaultManager{Profile: m.Profile}
+			if m.Auth != nil {
+				if diag := gohcl.DecodeBody(m.Auth, evalContext, vault); diag.HasErrors() {
+					return *nilGeneral, nil, &DecodeConfigFailed{File: c.CurrentConfig}
+				}
+			}
+			managers = append(managers, vault)
+		case "openbao":
+			bao := &VaultManager{Profile: m.Profile, openbao: true}
+			if m.Auth != nil {
+				if diag := gohcl.DecodeBody(m.Auth, evalContext, bao); diag.HasErrors() {
+					return *nilGeneral, nil, &DecodeConfigFailed{File: c.CurrentConfig}
+				}
+			}
+			managers = append(managers, bao)
+		case "azure":
+			azure := &AzureManager{Profile: m.Profile}
+			if diag := gohcl.DecodeBody(m.Auth, evalContext, azure); diag.HasErrors() {
+				return *nilGeneral, nil, &DecodeConfigFailed{File: c.CurrentConfig}
+			}
+			managers = append(managers, azure)
+		case "k8s":
+			kube := &K8sManager{Profile: m.Profile}
+			if m.Auth != nil {
+				if diag := gohcl.DecodeBody(m.Auth, evalContext, kube); diag.HasErrors() {
+					return *nilGeneral, nil, &DecodeConfigFailed{File: c.CurrentConfig}
+				}
+			}
+			managers = append(managers, kube)
+		case "1password":
+			op := &OnePasswordManager{Profile: m.Profile}
+			if m.Auth != nil {
+				if diag := gohcl.DecodeBody(m.Auth, evalContext, op); diag.HasErrors() {
+					return *nilGeneral, nil, &DecodeConfigFailed{File: c.CurrentConfig}
+				}
+			}
+			managers = append(managers, op)
+		case "doppler":
+			dp := &DopplerManager{Profile: m.Profile}
+			if m.Auth != nil {
+				if diag := gohcl.DecodeBody(m.Auth, evalContext, dp); diag.HasErrors() {
+					return *nilGeneral, nil, &DecodeConfigFailed{File: c.CurrentConfig}
+				}
+			}
+			managers = append(managers, dp)
+		case "bitwarden":
+			bw := &BitwardenManager{Profile: m.Profile}
+			if m.Auth != nil {
+				if diag := gohcl.DecodeBody(m.Auth, evalContext, bw); diag.HasErrors() {
+					return *nilGeneral, nil, &DecodeConfigFailed{File: c.CurrentConfig}
+				}
+			}
+			managers = append(managers, bw)
+		case "custom":
+			custom := &PluginManager{Profile: m.Profile}
+			if diag := gohcl.DecodeBody(m.Auth, evalContext, custom); diag.HasErrors() {
+				return *nilGeneral, nil, &DecodeConfigFailed{File: c.CurrentConfig}
+			}
+			managers = append(managers, custom)
+		case "chain":
+			chain := &ChainManager{Profile: m.Profile}
+			if diag := gohcl.DecodeBody(m.Auth, evalContext, chain); diag.HasErrors() {
+				return *nilGeneral, nil, &DecodeConfigFailed{File: c.CurrentConfig}
+			}
+			managers = append(managers, chain)
 		default:
-			return *nilGeneral, nil, fmt.Errorf("error in ReadConfig: unknown platform `%s`", managerPlatform)
+			return *nilGeneral, nil, Errorf(KindValidation, "error in ReadConfig: unknown platform `%s`", managerPlatform)
+		}
+	}
+	for _, m := range managers {
+		if chain, ok := m.(*ChainManager); ok {
+			if err := chain.resolve(managers); err != nil {
+				return *nilGeneral, nil, fmt.Errorf("error in ReadConfig: %w", err)
+			}
 		}
 	}
+	setProfiles(managers)
+	c.Conf = *configHCL
 	return configHCL.General, managers, nil
 }
 
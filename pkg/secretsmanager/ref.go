@@ -0,0 +1,192 @@
+package secretsmanager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// refSeparator splits a profile from a secret ID, i.e. aws-prod:app/db-password
+const refSeparator = ":"
+
+// arnPrefix starts aws ARNs, which hold the separator without pinning a profile
+const arnPrefix = "arn:"
+
+// profiles are the configured profile names, only these pin a reference
+var profiles = map[string]bool{}
+
+// setProfiles records the profiles of the managers so references can tell a pinned profile from a secret ID
+// holding the separator
+func setProfiles(managers []Manager) {
+	profiles = map[string]bool{}
+	for _, m := range managers {
+		profiles[m.ProfileName()] = true
+	}
+}
+
+// PinRef returns the reference pinning the secret ID to the profile
+func PinRef(profile, secretID string) string {
+	return profile + refSeparator + secretID
+}
+
+// ParseSecretRef splits a secret reference into its pinned profile and secret ID, the profile is empty when
+// the reference is a bare secret ID. Only a configured profile pins a reference so ARNs and other IDs holding
+// the separator are kept whole.
+func ParseSecretRef(ref string) (string, string) {
+	if strings.HasPrefix(ref, arnPrefix) {
+		return "", ref
+	}
+	kv := strings.SplitN(ref, refSeparator, 2)
+	if len(kv) != 2 || !profiles[kv[0]] {
+		return "", ref
+	}
+	return kv[0], kv[1]
+}
+
+// FindManager returns the manager with the matching profile name
+func FindManager(managers []Manager, profile string) (Manager, error) {
+	for _, m := range managers {
+		if m.ProfileName() == profile {
+			return m, nil
+		}
+	}
+	return nil, Errorf(KindNotFound, "no manager configured with profile `%s`", profile)
+}
+
+// GetPinned gets secrets using references that may pin a profile, bare references are pulled
+// from the default manager unless profileOrder says otherwise. The returned secrets keep the ID without the profile.
+func GetPinned(managers []Manager, defaultManager Manager, refs []string, profileOrder []string) ([]Secret, error) {
+	if Secrets, served, err := agent.getPinned(defaultManager.ProfileName(), refs); served {
+		return Secrets, err
+	}
+	var bare []string
+	var order []Manager
+	ids := map[Manager][]string{}
+	for _, ref := range refs {
+		profile, id := ParseSecretRef(ref)
+		if profile == "" {
+			bare = append(bare, id)
+			continue
+		}
+		m, err := FindManager(managers, profile)
+		if err != nil {
+			return []Secret{}, err
+		}
+		if _, ok := ids[m]; !ok {
+			order = append(order, m)
+		}
+		ids[m] = append(ids[m], id)
+	}
+
+	Secrets, err := getBare(managers, defaultManager, bare, profileOrder)
+	if IsInterrupted(err) {
+		return Secrets, err
+	}
+	if err != nil {
+		return []Secret{}, err
+	}
+	for _, m := range order {
+		s, err := m.Get(ids[m])
+		if IsInterrupted(err) {
+			return append(Secrets, s...), fmt.Errorf("%s: %w", m.ProfileName(), err)
+		}
+		if err != nil {
+			return []Secret{}, fmt.Errorf("%s: %w", m.ProfileName(), err)
+		}
+		Secrets = append(Secrets, s...)
+	}
+	return Secrets, nil
+}
+
+// getBare gets unqualified secret IDs. Every profile is listed for the IDs and when more than one holds the same
+// secret the candidates are listed, the first profile of profileOrder holding it is used, then the default profile.
+// The values are only pulled from the profile chosen, IDs neither of them holds are left to the default profile.
+func getBare(managers []Manager, defaultManager Manager, ids []string, profileOrder []string) ([]Secret, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	// a chain already defines which profile wins
+	if _, isChain := defaultManager.(*ChainManager); isChain || len(managers) < 2 {
+		return defaultManager.Get(ids)
+	}
+
+	// the profiles of the order, then the default profile, then every other profile
+	var preferred []Manager
+	for _, p := range append(append([]string{}, profileOrder...), defaultManager.ProfileName()) {
+		m, err := FindManager(managers, p)
+		if err != nil {
+			return []Secret{}, err
+		}
+		if !containsManager(preferred, m) {
+			preferred = append(preferred, m)
+		}
+	}
+	candidates := append([]Manager{}, preferred...)
+	for _, m := range managers {
+		if !containsManager(candidates, m) {
+			candidates = append(candidates, m)
+		}
+	}
+
+	found := map[string][]string{}
+	for _, m := range candidates {
+		if _, isChain := m.(*ChainManager); isChain {
+			continue
+		}
+		infos, err := ListQuery(m, Query{IDs: ids})
+		if IsInterrupted(err) {
+			return []Secret{}, fmt.Errorf("%s: %w", m.ProfileName(), err)
+		} else if err != nil {
+			if m == defaultManager {
+				return []Secret{}, fmt.Errorf("%s: %w", m.ProfileName(), err)
+			}
+			color.Yellow("skipping profile %s while looking up secrets: %v", m.ProfileName(), err)
+			continue
+		}
+		for _, info := range infos {
+			found[info.ID] = append(found[info.ID], m.ProfileName())
+		}
+	}
+
+	var order []Manager
+	chosen := map[Manager][]string{}
+	for _, id := range ids {
+		m := defaultManager
+		if profiles := found[id]; len(profiles) != 0 {
+			// the preferred profiles are listed first, the first profile holding the secret wins when it is one of them
+			if winner, err := FindManager(preferred, profiles[0]); err != nil {
+				color.Yellow("%s is not in profile %s but was found in %s, pin one with profile:%s", id, defaultManager.ProfileName(), strings.Join(profiles, ", "), id)
+			} else {
+				m = winner
+				if len(profiles) > 1 {
+					color.Yellow("%s exists in profiles %s, using %s (set profile_order or pin with profile:%s)", id, strings.Join(profiles, ", "), m.ProfileName(), id)
+				}
+			}
+		}
+		if _, ok := chosen[m]; !ok {
+			order = append(order, m)
+		}
+		chosen[m] = append(chosen[m], id)
+	}
+
+	var Secrets []Secret
+	for _, m := range order {
+		s, err := m.Get(chosen[m])
+		Secrets = append(Secrets, s...)
+		if err != nil {
+			return Secrets, fmt.Errorf("%s: %w", m.ProfileName(), err)
+		}
+	}
+	return Secrets, nil
+}
+
+// containsManager reports whether m is one of the managers
+func containsManager(managers []Manager, m Manager) bool {
+	for _, c := range managers {
+		if c == m {
+			return true
+		}
+	}
+	return false
+}
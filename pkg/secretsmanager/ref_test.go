@@ -0,0 +1,30 @@
+package secretsmanager
+
+import "testing"
+
+func TestParseSecretRef(t *testing.T) {
+	defer setProfiles(nil)
+	setProfiles([]Manager{&EnvManager{Profile: "aws-prod"}, &EnvManager{Profile: "local"}})
+	tests := []struct {
+		ref         string
+		wantProfile string
+		wantID      string
+	}{
+		{"app/db-password", "", "app/db-password"},
+		{"aws-prod:app/db-password", "aws-prod", "app/db-password"},
+		{"local:DB_PASSWORD", "local", "DB_PASSWORD"},
+		{"unknown:app/db-password", "", "unknown:app/db-password"},
+		{"aws-prod:", "aws-prod", ""},
+		{"arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db", "", "arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db"},
+		{"app/url:8080", "", "app/url:8080"},
+	}
+	for _, tt := range tests {
+		profile, id := ParseSecretRef(tt.ref)
+		if profile != tt.wantProfile || id != tt.wantID {
+			t.Errorf("ParseSecretRef(%q) = %q, %q, want %q, %q", tt.ref, profile, id, tt.wantProfile, tt.wantID)
+		}
+	}
+	if profile, id := ParseSecretRef(PinRef("local", "a/b")); profile != "local" || id != "a/b" {
+		t.Errorf("ParseSecretRef(PinRef) = %q, %q", profile, id)
+	}
+}
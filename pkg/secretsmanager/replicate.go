@@ -0,0 +1,55 @@
+package secretsmanager
+
+import (
+	"context"
+
+	"github.com/jacbart/jaws/internal/aws"
+)
+
+// AWSManager ReplicateSecrets replicates the secrets to the regions, or to replica_regions without any, regions
+// a secret is already replicated to are skipped. It returns the number of secrets replication was started for.
+func (a *AWSManager) ReplicateSecrets(secretIDs []string, regions []string, dryRun bool) (int, error) {
+	if len(regions) == 0 {
+		regions = a.ReplicaRegions
+	}
+	if len(regions) == 0 {
+		return 0, Errorf(KindValidation, "no regions given and profile %s has no replica_regions", a.Profile)
+	}
+	if !dryRun {
+		if err := requireProtected("replicate", a.Profile); err != nil {
+			return 0, err
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := LoadAWSClient(a, ctx)
+	if err != nil {
+		return 0, err
+	}
+	replicated := 0
+	for _, id := range secretIDs {
+		added, err := aws.ReplicateSecret(ctx, client, id, regions, dryRun)
+		if err != nil {
+			return replicated, err
+		}
+		if len(added) != 0 {
+			replicated++
+		}
+	}
+	return replicated, nil
+}
+
+// ChainManager ReplicateSecrets
+func (c *ChainManager) ReplicateSecrets(secretIDs []string, regions []string, dryRun bool) (int, error) {
+	r, ok := c.managers[0].(Replicator)
+	if !ok {
+		return 0, Errorf(KindUnsupported, "profile %s can not replicate secrets", c.managers[0].ProfileName())
+	}
+	if !dryRun {
+		if err := requireProtected("replicate", c.Profile); err != nil {
+			return 0, err
+		}
+	}
+	return r.ReplicateSecrets(secretIDs, regions, dryRun)
+}
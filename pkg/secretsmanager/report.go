@@ -0,0 +1,115 @@
+package secretsmanager
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// nearLimit is the share of the provider limit from which a secret is flagged as near it
+const nearLimit = 0.9
+
+// SizeReport is the size of a secret value and the encoding problems found in it
+type SizeReport struct {
+	ID   string
+	Size int
+	// Limit is the largest value the provider of the secret accepts, 0 when it has no fixed limit
+	Limit  int
+	Issues []string
+}
+
+// SizeLimit returns the largest secret value in bytes the provider of the manager accepts, 0 when there is no
+// fixed limit. A chain reports the limit of the profile it pushes to.
+func SizeLimit(m Manager) int {
+	switch m := m.(type) {
+	case *AWSManager:
+		return 65536
+	case *AzureManager:
+		return 25 * 1024
+	case *K8sManager:
+		// the limit is for the whole secret object, the other keys and metadata count towards it as well
+		return 1024 * 1024
+	case *ChainManager:
+		if len(m.managers) != 0 {
+			return SizeLimit(m.managers[0])
+		}
+	}
+	return 0
+}
+
+// ReportSizes returns the size of every secret with the problems found in it: values near or over the
+// provider limit, a UTF-8 byte order mark, CRLF line endings and values that are base64 of base64
+func ReportSizes(m Manager, Secrets []Secret) []SizeReport {
+	limit := SizeLimit(m)
+	reports := make([]SizeReport, 0, len(Secrets))
+	for _, s := range Secrets {
+		r := SizeReport{ID: s.ID, Size: len(s.Content), Limit: limit}
+		if s.IsBinary() {
+			r.Size = len(s.Binary)
+		}
+		switch {
+		case limit != 0 && r.Size > limit:
+			r.Issues = append(r.Issues, fmt.Sprintf("over the %d byte limit", limit))
+		case limit != 0 && float64(r.Size) >= nearLimit*float64(limit):
+			r.Issues = append(r.Issues, fmt.Sprintf("near the %d byte limit", limit))
+		}
+		if !s.IsBinary() {
+			r.Issues = append(r.Issues, encodingIssues(s.Content)...)
+		}
+		reports = append(reports, r)
+	}
+	return reports
+}
+
+// encodingIssues returns the encoding problems of a value that often break parsing in applications
+func encodingIssues(value string) []string {
+	var issues []string
+	if strings.HasPrefix(value, "\ufeff") {
+		issues = append(issues, "UTF-8 byte order mark")
+	}
+	if strings.Contains(value, "\r\n") {
+		issues = append(issues, "CRLF line endings")
+	}
+	// a single encoding of a random token looks like base64 again once decoded, only a second decode giving
+	// readable text shows the value was encoded twice
+	if decoded, ok := decodeBase64(value); ok {
+		if twice, ok := decodeBase64(decoded); ok && printable(twice) {
+			issues = append(issues, "base64 encoded twice")
+		}
+	}
+	return issues
+}
+
+// printable reports whether s is UTF-8 text without control characters other than whitespace
+func printable(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// base64Value matches values made of base64 characters only, standard or url safe
+var base64Value = regexp.MustCompile(`^[A-Za-z0-9+/_-]+={0,2}$`)
+
+// decodeBase64 decodes a value that is entirely base64, short values are not decoded as too many words and
+// passwords happen to be valid base64
+func decodeBase64(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < 8 || !base64Value.MatchString(value) {
+		return "", false
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if decoded, err := enc.DecodeString(value); err == nil {
+			return string(decoded), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,68 @@
+package secretsmanager
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func TestEncodingIssues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"plain", "hunter2", nil},
+		{"token", "sk4f9Qz7Lm2Pw8Rt", nil},
+		{"base64 of a token", b64("sk4f9Qz7Lm2Pw8Rt"), nil},
+		{"base64 of a long token", b64("AKIAIOSFODNN7EXAMPLEwJalrXUtnFEMIK7MDENG"), nil},
+		{"base64 of text", b64("supersecretvalue"), nil},
+		{"base64 of json", b64(`{"user":"app","password":"x"}`), nil},
+		{"twice", b64(b64("supersecretvalue")), []string{"base64 encoded twice"}},
+		{"twice json", b64(b64(`{"user":"app","password":"x"}`)), []string{"base64 encoded twice"}},
+		{"twice with newline", b64(b64("line one\nline two")) + "\n", []string{"base64 encoded twice"}},
+		{"bom", "\ufeffvalue", []string{"UTF-8 byte order mark"}},
+		{"crlf", "a=1\r\nb=2\r\n", []string{"CRLF line endings"}},
+		{"lf", "a=1\nb=2\n", nil},
+		{"bom and crlf", "\ufeffa=1\r\n", []string{"UTF-8 byte order mark", "CRLF line endings"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encodingIssues(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("encodingIssues(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportSizes(t *testing.T) {
+	m := &AzureManager{}
+	limit := SizeLimit(m)
+	Secrets := []Secret{
+		{ID: "small", Content: "x"},
+		{ID: "near", Content: string(make([]byte, limit*9/10))},
+		{ID: "over", Content: string(make([]byte, limit+1))},
+		{ID: "binary", Binary: make([]byte, 10)},
+	}
+	want := map[string][]string{
+		"small":  nil,
+		"near":   {"near the 25600 byte limit"},
+		"over":   {"over the 25600 byte limit"},
+		"binary": nil,
+	}
+	for _, r := range ReportSizes(m, Secrets) {
+		if !reflect.DeepEqual(r.Issues, want[r.ID]) {
+			t.Errorf("%s: issues %q, want %q", r.ID, r.Issues, want[r.ID])
+		}
+		if r.Limit != limit {
+			t.Errorf("%s: limit %d, want %d", r.ID, r.Limit, limit)
+		}
+	}
+	if got := SizeLimit(&EnvManager{}); got != 0 {
+		t.Errorf("SizeLimit(env) = %d, want 0", got)
+	}
+}
@@ -9,6 +9,19 @@ import (
 
 // AWSManager Rollback
 func (a *AWSManager) Rollback() error {
+	return a.RollbackSteps(1)
+}
+
+// AWSManager RollbackSteps rolls the selected secrets back the given number of versions using the jaws-vN stages
+func (a *AWSManager) RollbackSteps(steps int) error {
+	defer a.cache.reset()
+	if steps < 1 || (steps > 1 && steps > aws.MaxStages) {
+		most := aws.MaxStages
+		if most < 1 {
+			most = 1
+		}
+		return Errorf(KindValidation, "steps must be between 1 and %d, see keep_versions", most)
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -21,12 +34,41 @@ func (a *AWSManager) Rollback() error {
 	if err != nil {
 		return fmt.Errorf("error while iterating and printing secret names: %v", err)
 	}
+	if err = requireChange("rollback", a.Profile, sID); err != nil {
+		return err
+	}
 
-	l := len(sID) - 1
-	for i := 0; i < l; i++ {
-		if err = aws.RollbackSecret(ctx, client, sID[i]); err != nil {
+	var rolledBack []string
+	defer func() { notify("rollback", a.Profile, rolledBack) }()
+	for _, id := range sID {
+		if err = aws.RollbackSecret(ctx, client, id, steps); err != nil {
 			return err
 		}
+		rolledBack = append(rolledBack, id)
+	}
+	return nil
+}
+
+// SetKeepVersions sets how many older versions keep a jaws-vN stage, labels past the limit are removed on the next push
+func SetKeepVersions(keep *int) error {
+	if keep == nil {
+		return nil
 	}
+	if *keep < 0 {
+		return Errorf(KindValidation, "keep_versions can not be negative")
+	}
+	aws.MaxStages = *keep
 	return nil
 }
+
+// ChainManager RollbackSteps
+func (c *ChainManager) RollbackSteps(steps int) error {
+	r, ok := c.managers[0].(VersionRollbacker)
+	if !ok {
+		return Errorf(KindUnsupported, "profile %s can only roll back one version", c.managers[0].ProfileName())
+	}
+	if err := requireProtected("rollback", c.Profile); err != nil {
+		return err
+	}
+	return r.RollbackSteps(steps)
+}
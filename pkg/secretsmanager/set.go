@@ -5,14 +5,46 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/fatih/color"
 	"github.com/jacbart/jaws/internal/aws"
+	"github.com/jacbart/jaws/utils/helpers"
 )
 
+// PushOptions are the settings given to jaws set for the secrets it creates, they override the settings
+// of the manager
+type PushOptions struct {
+	Tags     map[string]string
+	KMSKeyID string
+}
+
+var pushOptions PushOptions
+
+// SetPushOptions sets the options of the secrets created by this run, only aws managers support them
+func SetPushOptions(m Manager, o PushOptions) error {
+	if c, ok := m.(*ChainManager); ok {
+		m = c.managers[0]
+	}
+	if _, ok := m.(*AWSManager); !ok {
+		switch {
+		case len(o.Tags) != 0:
+			return Errorf(KindUnsupported, "profile %s does not support tags on created secrets", m.ProfileName())
+		case o.KMSKeyID != "":
+			return Errorf(KindUnsupported, "profile %s does not support kms keys", m.ProfileName())
+		}
+	}
+	pushOptions = o
+	return nil
+}
+
 // AWSManager Set
 func (a *AWSManager) Set(secretsPath string, createPrompt bool) error {
-	ctx, cancel := context.WithCancel(context.Background())
+	defer a.cache.reset()
+	ctx, cancel := context.WithCancel(helpers.Context())
 	defer cancel()
 
 	client, err := LoadAWSClient(a, ctx)
@@ -20,33 +52,124 @@ func (a *AWSManager) Set(secretsPath string, createPrompt bool) error {
 		return err
 	}
 
-	sID, err := aws.GetSecretNames(secretsPath)
+	sID, err := helpers.GetSecretNames(secretsPath)
 	if err != nil {
 		return err
 	}
+	if err = requireChange("push", a.Profile, sID); err != nil {
+		return err
+	}
+
+	var existing []string
+	listed := false
+	similar := func(secretID string) []string {
+		if helpers.SuggestDistance < 0 {
+			return nil
+		}
+		if !listed {
+			existing, _ = a.ListAll()
+			listed = true
+		}
+		return helpers.Suggest(secretID, existing)
+	}
+
+	var pushed []string
+	defer func() {
+		trackRecent(a.Profile, pushed...)
+		notify("push", a.Profile, pushed)
+	}()
 
 	l := len(sID)
 	var secretUpdate []byte
+	defer helpers.StartWork()()
 	for i := 0; i < l; i++ {
+		if err = interrupted("push", sID[:i], sID[i:]); err != nil {
+			return err
+		}
 		secretUpdate, err = ioutil.ReadFile(fmt.Sprintf("%s/%s", secretsPath, sID[i]))
 		if err != nil {
 			return err
 		}
-		shouldSecretUpdate, err := aws.CheckIfUpdate(ctx, client, sID[i], string(secretUpdate))
+		if utf8.Valid(secretUpdate) {
+			helpers.AddRedaction(string(secretUpdate))
+		}
+		shouldSecretUpdate, err := aws.CheckIfUpdate(ctx, client, sID[i], secretUpdate)
 		if err != nil {
-			return nil
+			if stopErr := interrupted("push", sID[:i], sID[i:]); stopErr != nil {
+				return stopErr
+			}
+			return err
 		}
 		if shouldSecretUpdate {
-			if err = aws.HandleUpdateCreate(ctx, client, sID[i], string(secretUpdate), createPrompt); err != nil {
+			err = aws.HandleUpdateCreate(ctx, client, sID[i], secretUpdate, createPrompt, a.createOptions(), similar)
+			progressPush(a.Profile, sID[i], err, true)
+			if err != nil {
+				if stopErr := interrupted("push", sID[:i], sID[i:]); stopErr != nil {
+					return stopErr
+				}
 				return err
 			}
+			pushed = append(pushed, sID[i])
+			a.tagChangedBy(ctx, client, sID[i])
 		} else {
-			fmt.Printf("%s %s\n", sID[i], color.CyanString("skipped"))
+			helpers.Infof("%s %s\n", sID[i], color.CyanString("skipped"))
+			progressPush(a.Profile, sID[i], nil, false)
 		}
 	}
 	return nil
 }
 
+// createOptions returns the settings of the secrets created by a push, the options of the push override the
+// settings of the manager
+func (a *AWSManager) createOptions() aws.CreateOptions {
+	tags := map[string]string{}
+	for k, v := range a.Tags {
+		tags[k] = v
+	}
+	for k, v := range pushOptions.Tags {
+		tags[k] = v
+	}
+	kmsKeyID := a.KMSKeyID
+	if pushOptions.KMSKeyID != "" {
+		kmsKeyID = pushOptions.KMSKeyID
+	}
+	return aws.CreateOptions{Tags: tags, KMSKeyID: kmsKeyID, ReplicaRegions: a.ReplicaRegions}
+}
+
+// tagChangedBy records who pushed the secret, failing to do so only prints a warning
+func (a *AWSManager) tagChangedBy(ctx context.Context, client *secretsmanager.Client, secretID string) {
+	identity, err := a.identity(ctx)
+	if err == nil {
+		err = aws.SetChangedBy(ctx, client, secretID, identity)
+	}
+	if err != nil {
+		color.Yellow("unable to tag %s with %s: %v", secretID, aws.ChangedByTag, err)
+	}
+}
+
+// PushValues pushes the values as the secrets they are mapped to through the Set of the manager, the values
+// are staged in a private temporary directory that is removed afterwards
+func PushValues(m Manager, values map[string]string, createPrompt bool) error {
+	dir, err := os.MkdirTemp("", "jaws-push-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	for id, value := range values {
+		path := filepath.Join(dir, id)
+		if !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return Errorf(KindValidation, "`%s` is not a valid secret ID", id)
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		if err = os.WriteFile(path, []byte(value), 0600); err != nil {
+			return err
+		}
+	}
+	return m.Set(dir, createPrompt)
+}
+
 // SetPostRun
 func SetPostRun(secretsPath string, cleanLocalSecrets bool) error {
 	if !cleanLocalSecrets {
@@ -54,7 +177,20 @@ func SetPostRun(secretsPath string, cleanLocalSecrets bool) error {
 		if err != nil {
 			return nil
 		}
-		color.Red("folder '%s' deleted\n", secretsPath)
+		helpers.Infof("%s\n", color.RedString("folder '%s' deleted", secretsPath))
 	}
 	return nil
 }
+
+// progressPush reports the result of pushing a secret, changed is false for secrets skipped as unchanged
+func progressPush(profile string, secretID string, err error, changed bool) {
+	e := helpers.ProgressEvent{Event: helpers.EventPushResult, Profile: profile, Secret: secretID, Result: "skipped"}
+	switch {
+	case err != nil:
+		e.Result = "failed"
+		e.Error = err.Error()
+	case changed:
+		e.Result = "pushed"
+	}
+	helpers.Progress(e)
+}
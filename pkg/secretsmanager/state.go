@@ -0,0 +1,104 @@
+package secretsmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// OutputSecrets is the output category for secrets downloaded to the secrets path
+const OutputSecrets = "secrets"
+
+// Outputs maps an output category to the absolute paths of files jaws has written
+type Outputs map[string][]string
+
+// outputsFile returns the path of the state file tracking written files
+func outputsFile() string {
+	return helpers.StatePath("outputs.json")
+}
+
+// LoadOutputs reads the tracked outputs, a missing state file returns no outputs
+func LoadOutputs() (Outputs, error) {
+	o := Outputs{}
+	if err := loadState(outputsFile(), &o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// TrackOutputs records the files under the given category so jaws clean can find them later
+func TrackOutputs(category string, paths ...string) error {
+	o, err := LoadOutputs()
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, p := range o[category] {
+		seen[p] = true
+	}
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		if !seen[abs] {
+			seen[abs] = true
+			o[category] = append(o[category], abs)
+		}
+	}
+	sort.Strings(o[category])
+	return o.save()
+}
+
+// Prune drops any tracked file that no longer exists
+func (o Outputs) Prune() {
+	for category, paths := range o {
+		var kept []string
+		for _, p := range paths {
+			if _, err := os.Stat(p); err == nil {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == 0 {
+			delete(o, category)
+		} else {
+			o[category] = kept
+		}
+	}
+}
+
+func (o Outputs) save() error {
+	return saveState(outputsFile(), o)
+}
+
+// loadState decodes a json state file into v, v is left untouched when the file does not exist
+func loadState(file string, v interface{}) error {
+	src, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(src, v); err != nil {
+		return fmt.Errorf("reading %s: %w", file, err)
+	}
+	return nil
+}
+
+// saveState writes v to a json state file readable only by the user
+func saveState(file string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	src, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, src, 0600)
+}
@@ -0,0 +1,63 @@
+package secretsmanager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jacbart/jaws/utils/helpers"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+// switchLabel starts the fuzzy finder entries that switch to another profile
+const switchLabel = "» profile "
+
+// SwitchFind opens the fuzzy finder over the secrets of the manager with an entry for each other profile,
+// selecting one of them lists the secrets of that profile instead. The fuzzy finder has no custom keybindings
+// so switching is done by selecting the entry. Returns the manager the secrets were selected from.
+func SwitchFind(managers []Manager, m Manager) (Manager, []string, error) {
+	picks, err := LoadPicks()
+	if err != nil {
+		helpers.Verbosef(helpers.VerbosityVerbose, "loading favorite and recent secrets: %v\n", err)
+	}
+	for {
+		ids, err := m.ListAll()
+		if err != nil {
+			return m, nil, fmt.Errorf("%s: %w", m.ProfileName(), err)
+		}
+		ids = picks.withPicks(ids, m.ProfileName())
+		var others []Manager
+		for _, other := range managers {
+			if other != m {
+				others = append(others, other)
+			}
+		}
+		entries := make([]string, 0, len(others)+len(ids))
+		for _, other := range others {
+			entries = append(entries, switchLabel+other.ProfileName())
+		}
+		for _, id := range ids {
+			entries = append(entries, picks.label(id, m.ProfileName()))
+		}
+		idxs, err := fuzzyfinder.FindMulti(entries, func(i int) string {
+			return entries[i]
+		}, fuzzyfinder.WithHeader(fmt.Sprintf("profile %s, select a %s entry to switch", m.ProfileName(), strings.TrimSpace(switchLabel))))
+		if err != nil && !errors.Is(err, fuzzyfinder.ErrAbort) {
+			return m, nil, err
+		}
+
+		var selected []string
+		var next Manager
+		for _, idx := range idxs {
+			if idx < len(others) {
+				next = others[idx]
+				continue
+			}
+			selected = append(selected, ids[idx-len(others)])
+		}
+		if next == nil {
+			return m, selected, nil
+		}
+		m = next
+	}
+}
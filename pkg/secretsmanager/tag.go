@@ -0,0 +1,170 @@
+package secretsmanager
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jacbart/jaws/internal/aws"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// MatchSecrets returns the secrets of the manager matching any of the glob patterns, the literal start
+// of the patterns is used to narrow the listing on the provider side
+func MatchSecrets(m Manager, patterns []string) ([]string, error) {
+	var q Query
+	for _, pattern := range patterns {
+		prefix := globPrefix(pattern)
+		if prefix == "" {
+			q.Prefixes = nil
+			break
+		}
+		q.Prefixes = append(q.Prefixes, prefix)
+	}
+	infos, err := ListQuery(m, q)
+	if err != nil {
+		return nil, err
+	}
+	var matched []string
+	for _, id := range secretIDs(infos) {
+		for _, pattern := range patterns {
+			if helpers.GlobMatch(pattern, id) {
+				matched = append(matched, id)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// AWSManager TagSecrets
+func (a *AWSManager) TagSecrets(secretIDs []string, tags map[string]string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := requireChange("tag", a.Profile, secretIDs); err != nil {
+		return err
+	}
+	client, err := LoadAWSClient(a, ctx)
+	if err != nil {
+		return err
+	}
+	var tagged []string
+	defer func() { notify("tag", a.Profile, tagged) }()
+	for _, id := range secretIDs {
+		if err = aws.TagSecret(ctx, client, id, tags); err != nil {
+			return err
+		}
+		tagged = append(tagged, id)
+	}
+	return nil
+}
+
+// AWSManager UntagSecrets
+func (a *AWSManager) UntagSecrets(secretIDs []string, keys []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := requireChange("tag", a.Profile, secretIDs); err != nil {
+		return err
+	}
+	client, err := LoadAWSClient(a, ctx)
+	if err != nil {
+		return err
+	}
+	var untagged []string
+	defer func() { notify("tag", a.Profile, untagged) }()
+	for _, id := range secretIDs {
+		if err = aws.UntagSecret(ctx, client, id, keys); err != nil {
+			return err
+		}
+		untagged = append(untagged, id)
+	}
+	return nil
+}
+
+// ChainManager TagSecrets
+func (c *ChainManager) TagSecrets(secretIDs []string, tags map[string]string) error {
+	t, ok := c.managers[0].(Tagger)
+	if !ok {
+		return Errorf(KindUnsupported, "profile %s does not support tags", c.managers[0].ProfileName())
+	}
+	if err := requireProtected("tag", c.Profile); err != nil {
+		return err
+	}
+	if err := c.requireFirst(secretIDs); err != nil {
+		return err
+	}
+	return t.TagSecrets(secretIDs, tags)
+}
+
+// ChainManager UntagSecrets
+func (c *ChainManager) UntagSecrets(secretIDs []string, keys []string) error {
+	t, ok := c.managers[0].(Tagger)
+	if !ok {
+		return Errorf(KindUnsupported, "profile %s does not support tags", c.managers[0].ProfileName())
+	}
+	if err := requireProtected("tag", c.Profile); err != nil {
+		return err
+	}
+	if err := c.requireFirst(secretIDs); err != nil {
+		return err
+	}
+	return t.UntagSecrets(secretIDs, keys)
+}
+
+// AWSManager SetDescription
+func (a *AWSManager) SetDescription(secretID string, description string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := requireChange("tag", a.Profile, []string{secretID}); err != nil {
+		return err
+	}
+	client, err := LoadAWSClient(a, ctx)
+	if err != nil {
+		return err
+	}
+	if err = aws.SetDescription(ctx, client, secretID, description); err != nil {
+		return err
+	}
+	notify("tag", a.Profile, []string{secretID})
+	return nil
+}
+
+// ChainManager SetDescription
+func (c *ChainManager) SetDescription(secretID string, description string) error {
+	d, ok := c.managers[0].(DescriptionSetter)
+	if !ok {
+		return Errorf(KindUnsupported, "profile %s does not support descriptions", c.managers[0].ProfileName())
+	}
+	if err := requireProtected("tag", c.Profile); err != nil {
+		return err
+	}
+	if err := c.requireFirst([]string{secretID}); err != nil {
+		return err
+	}
+	return d.SetDescription(secretID, description)
+}
+
+// requireFirst fails when a secret is not in the first profile of the chain, the secrets are listed across every
+// profile but their metadata can only be changed in the first one
+func (c *ChainManager) requireFirst(secretIDs []string) error {
+	infos, err := ListQuery(c.managers[0], Query{IDs: secretIDs})
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
+	for _, info := range infos {
+		found[info.ID] = true
+	}
+	var missing []string
+	for _, id := range secretIDs {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) != 0 {
+		return Errorf(KindNotFound, "%s not in profile %s, chain %s only changes secrets of its first profile", strings.Join(missing, ", "), c.managers[0].ProfileName(), c.Profile)
+	}
+	return nil
+}
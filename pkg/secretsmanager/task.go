@@ -0,0 +1,104 @@
+package secretsmanager
+
+import (
+	"sort"
+
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// TaskHCL is a project task run with jaws run NAME, i.e.
+//
+//	task "up" {
+//	  pull = ["@tls"]
+//	  env = ["DATABASE_URL=prod/app/db-url"]
+//	  env_file = ".env"
+//	  run = ["docker compose up"]
+//	}
+//
+// The tasks it depends on run first, then the secrets of pull are written to the secrets path, the env secrets
+// are written to env_file and every command of run is run with the env secrets set.
+type TaskHCL struct {
+	Name        string   `hcl:"name,label"`
+	Description string   `hcl:"description,optional"`
+	Depends     []string `hcl:"depends,optional"`
+	Pull        []string `hcl:"pull,optional"`
+	// Env takes the same NAME=SECRET, SECRET and @group arguments as jaws exec
+	Env     []string `hcl:"env,optional"`
+	EnvFile string   `hcl:"env_file,optional"`
+	Run     []string `hcl:"run,optional"`
+}
+
+var tasks = map[string]TaskHCL{}
+
+// SetTasks sets the tasks jaws run can run
+func SetTasks(t []TaskHCL) error {
+	tasks = map[string]TaskHCL{}
+	for _, task := range t {
+		if _, ok := tasks[task.Name]; ok {
+			return Errorf(KindValidation, "task `%s` is defined more than once", task.Name)
+		}
+		if len(task.Depends) == 0 && len(task.Pull) == 0 && len(task.Env) == 0 && len(task.Run) == 0 {
+			return Errorf(KindValidation, "task `%s` needs depends, pull, env or run", task.Name)
+		}
+		if task.EnvFile != "" && len(task.Env) == 0 {
+			return Errorf(KindValidation, "task `%s` sets env_file without env", task.Name)
+		}
+		tasks[task.Name] = task
+	}
+	return nil
+}
+
+// TaskNames returns the sorted names of the configured tasks
+func TaskNames() []string {
+	names := make([]string, 0, len(tasks))
+	for name := range tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FindTask returns the task with the name
+func FindTask(name string) (TaskHCL, error) {
+	task, ok := tasks[name]
+	if !ok {
+		helpers.PrintSuggestions(name, helpers.Suggest(name, TaskNames()))
+		return task, Errorf(KindNotFound, "unknown task `%s`", name)
+	}
+	return task, nil
+}
+
+// TaskOrder returns the task and the tasks it depends on in the order they run, every task runs once
+// even when several tasks depend on it
+func TaskOrder(name string) ([]TaskHCL, error) {
+	var order []TaskHCL
+	done := map[string]bool{}
+	visiting := map[string]bool{}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		if done[name] {
+			return nil
+		}
+		if visiting[name] {
+			return Errorf(KindValidation, "task `%s` depends on itself through %v", name, append(path, name))
+		}
+		task, err := FindTask(name)
+		if err != nil {
+			return err
+		}
+		visiting[name] = true
+		for _, dep := range task.Depends {
+			if err = visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+		done[name] = true
+		order = append(order, task)
+		return nil
+	}
+	if err := visit(name, nil); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
@@ -0,0 +1,72 @@
+package secretsmanager
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// defaultTicketPattern matches issue keys like ABC-123
+const defaultTicketPattern = `^[A-Z][A-Z0-9]+-[0-9]+$`
+
+// TicketHCL requires a ticket reference on operations changing secrets under the listed prefixes
+type TicketHCL struct {
+	Prefixes []string `hcl:"prefixes"`
+	On       []string `hcl:"on,optional"`
+	Pattern  string   `hcl:"pattern,optional"`
+}
+
+var (
+	ticketRule *TicketHCL
+	ticket     string
+)
+
+// SetTicket sets the ticket rule and the ticket given for this run, the ticket is checked against the rule pattern
+func SetTicket(rule *TicketHCL, t string) error {
+	ticketRule = rule
+	ticket = t
+	if rule == nil {
+		return nil
+	}
+	if len(rule.On) == 0 {
+		rule.On = []string{"push", "delete"}
+	}
+	for _, event := range rule.On {
+		if !helpers.Contains(NotifyEvents, event) {
+			return Errorf(KindValidation, "ticket: unknown event `%s`, available events are %s", event, strings.Join(NotifyEvents, ","))
+		}
+	}
+	pattern := rule.Pattern
+	if pattern == "" {
+		pattern = defaultTicketPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Errorf(KindValidation, "ticket pattern: %w", err)
+	}
+	if t != "" && !re.MatchString(t) {
+		return Errorf(KindValidation, "ticket `%s` does not match %s", t, pattern)
+	}
+	return nil
+}
+
+// Ticket returns the ticket given for this run
+func Ticket() string {
+	return ticket
+}
+
+// requireTicket fails when the event changes a secret under one of the ticket prefixes and no ticket was given
+func requireTicket(event string, secretIDs []string) error {
+	if ticketRule == nil || ticket != "" || !helpers.Contains(ticketRule.On, event) {
+		return nil
+	}
+	for _, id := range secretIDs {
+		for _, prefix := range ticketRule.Prefixes {
+			if strings.HasPrefix(id, prefix) {
+				return Errorf(KindValidation, "%s matches %s, a ticket is required to %s it, pass --ticket", id, prefix, event)
+			}
+		}
+	}
+	return nil
+}
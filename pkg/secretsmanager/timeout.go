@@ -0,0 +1,44 @@
+package secretsmanager
+
+import (
+	"time"
+
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// TimeoutsHCL sets how long each kind of call to a secret manager may take, values are durations like 30s or 1m
+type TimeoutsHCL struct {
+	List string `hcl:"list,optional"`
+	Get  string `hcl:"get,optional"`
+	Push string `hcl:"push,optional"`
+}
+
+// SetTimeouts applies the configured timeouts, a non zero override replaces all of them
+func SetTimeouts(t *TimeoutsHCL, override time.Duration) error {
+	if t != nil {
+		for _, d := range []struct {
+			name   string
+			value  string
+			target *time.Duration
+		}{
+			{"list", t.List, &helpers.ListTimeout},
+			{"get", t.Get, &helpers.GetTimeout},
+			{"push", t.Push, &helpers.PushTimeout},
+		} {
+			if d.value == "" {
+				continue
+			}
+			timeout, err := time.ParseDuration(d.value)
+			if err != nil || timeout <= 0 {
+				return Errorf(KindValidation, "invalid %s timeout `%s`, use a duration like 30s", d.name, d.value)
+			}
+			*d.target = timeout
+		}
+	}
+	if override > 0 {
+		helpers.ListTimeout = override
+		helpers.GetTimeout = override
+		helpers.PushTimeout = override
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package secretsmanager
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// TrashedSecret is a secret scheduled for deletion that can still be restored
+type TrashedSecret struct {
+	ID           string
+	Profile      string
+	DeletionDate time.Time
+}
+
+// Remaining returns the time left to restore the secret
+func (t TrashedSecret) Remaining() string {
+	d := time.Until(t.DeletionDate)
+	if d < 0 {
+		return "0m"
+	}
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh", days, hours)
+	}
+	return fmt.Sprintf("%dh %dm", hours, int(d.Minutes())%60)
+}
+
+// ListTrash lists the secrets pending deletion in every profile that supports it
+func ListTrash(managers []Manager) ([]TrashedSecret, error) {
+	var trashed []TrashedSecret
+	for _, m := range managers {
+		if _, isChain := m.(*ChainManager); isChain {
+			continue
+		}
+		t, ok := m.(Trasher)
+		if !ok {
+			continue
+		}
+		list, err := t.ListTrash()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.ProfileName(), err)
+		}
+		trashed = append(trashed, list...)
+	}
+	sort.SliceStable(trashed, func(i, j int) bool {
+		return trashed[i].DeletionDate.Before(trashed[j].DeletionDate)
+	})
+	return trashed, nil
+}
+
+// RestoreTrash cancels the deletion of secrets, a reference without a profile is restored from the profile
+// whose trash holds it and falls back to the default manager when no trash does
+func RestoreTrash(managers []Manager, defaultManager Manager, refs []string) error {
+	var trashed []TrashedSecret
+	listed := false
+	for _, ref := range refs {
+		profile, id := ParseSecretRef(ref)
+		if profile == "" {
+			if !listed {
+				var err error
+				if trashed, err = ListTrash(managers); err != nil {
+					return err
+				}
+				listed = true
+			}
+			var profiles []string
+			for _, t := range trashed {
+				if t.ID == id {
+					profiles = append(profiles, t.Profile)
+				}
+			}
+			switch len(profiles) {
+			case 0:
+				profile = defaultManager.ProfileName()
+			case 1:
+				profile = profiles[0]
+			default:
+				return Errorf(KindConflict, "%s is in the trash of profiles %s, pin one with profile:%s", id, strings.Join(profiles, ", "), id)
+			}
+		}
+		m, err := FindManager(managers, profile)
+		if err != nil {
+			return err
+		}
+		if err = m.DeleteCancel([]string{id}); err != nil {
+			return fmt.Errorf("%s: %w", profile, err)
+		}
+	}
+	return nil
+}
+
+// PrintTrash writes the trashed secrets as a table
+func PrintTrash(w io.Writer, trashed []TrashedSecret) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROFILE\tNAME\tDELETED ON\tRECOVERABLE FOR")
+	for _, t := range trashed {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", t.Profile, t.ID, t.DeletionDate.Local().Format("2006-01-02 15:04"), t.Remaining())
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,554 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/internal/vault"
+	"github.com/jacbart/jaws/utils/helpers"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+// defaultKubernetesJWTPath is where kubernetes mounts the service account token of a pod
+const defaultKubernetesJWTPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultManager stores secrets in a HashiCorp Vault KV v2 engine, each secret is kept under one field of the
+// KV secret so pushes and pulls map onto KV versions. OpenBao speaks the same API, openbao managers read the
+// BAO_ variables before the VAULT_ ones.
+type VaultManager struct {
+	Profile       string
+	Address       string `hcl:"address,optional"`
+	Namespace     string `hcl:"namespace,optional"`
+	Mount         string `hcl:"mount,optional"`
+	Field         string `hcl:"field,optional"`
+	Auth          string `hcl:"auth,optional"`
+	AuthMount     string `hcl:"auth_mount,optional"`
+	Token         string `hcl:"token,optional"`
+	RoleID        string `hcl:"role_id,optional"`
+	SecretID      string `hcl:"secret_id,optional"`
+	Role          string `hcl:"role,optional"`
+	JWTPath       string `hcl:"jwt_path,optional"`
+	CACert        string `hcl:"ca_cert,optional"`
+	ClientCert    string `hcl:"client_cert,optional"`
+	ClientKey     string `hcl:"client_key,optional"`
+	TLSServerName string `hcl:"tls_server_name,optional"`
+	AgeRecipient  string `hcl:"age_recipient,optional"`
+	openbao       bool
+	client        *vault.Client
+}
+
+// platform returns the name of the manager used in messages
+func (v *VaultManager) platform() string {
+	if v.openbao {
+		return "openbao"
+	}
+	return "vault"
+}
+
+// getenv returns the BAO_ variable of an openbao manager, or the VAULT_ variable, i.e. getenv("ADDR")
+func (v *VaultManager) getenv(name string) string {
+	if v.openbao {
+		if value := os.Getenv("BAO_" + name); value != "" {
+			return value
+		}
+	}
+	return os.Getenv("VAULT_" + name)
+}
+
+// envName returns the variable named in messages, BAO_ADDR for openbao and VAULT_ADDR for vault
+func (v *VaultManager) envName(name string) string {
+	if v.openbao {
+		return "BAO_" + name
+	}
+	return "VAULT_" + name
+}
+
+// mount returns the path the KV v2 engine is mounted at, secret by default
+func (v *VaultManager) mount() string {
+	if v.Mount == "" {
+		return "secret"
+	}
+	return v.Mount
+}
+
+// field returns the field of the KV secret holding the secret content, value by default
+func (v *VaultManager) field() string {
+	if v.Field == "" {
+		return "value"
+	}
+	return v.Field
+}
+
+// loadClient returns a client logged in with the configured auth method, the client is reused for the rest of the run
+func (v *VaultManager) loadClient(ctx context.Context) (*vault.Client, error) {
+	if v.client != nil {
+		return v.client, nil
+	}
+	helpers.Verbosef(helpers.VerbosityDebug, "loading vault client for profile %s\n", v.Profile)
+	c := &vault.Client{
+		Address:   v.Address,
+		Namespace: v.Namespace,
+	}
+	if c.Address == "" {
+		c.Address = v.getenv("ADDR")
+	}
+	if c.Address == "" {
+		return nil, Errorf(KindValidation, "%s manager %s needs an address or %s", v.platform(), v.Profile, v.envName("ADDR"))
+	}
+	if c.Namespace == "" {
+		c.Namespace = v.getenv("NAMESPACE")
+	}
+	tlsConfig := v.tlsConfig()
+	httpClient, err := tlsConfig.HTTPClient()
+	if err != nil {
+		return nil, Errorf(KindValidation, "%s manager %s: %w", v.platform(), v.Profile, err)
+	}
+	c.HTTP = httpClient
+
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.GetTimeout)
+	defer cancel()
+	switch v.Auth {
+	case "", "token":
+		c.Token, err = v.token()
+	case "approle":
+		if v.RoleID == "" || v.SecretID == "" {
+			return nil, Errorf(KindValidation, "%s manager %s needs role_id and secret_id for approle auth", v.platform(), v.Profile)
+		}
+		helpers.AddRedaction(v.SecretID)
+		c.Token, err = c.Login(timeCtx, v.authMount("approle"), map[string]string{"role_id": v.RoleID, "secret_id": v.SecretID})
+	case "kubernetes":
+		if v.Role == "" {
+			return nil, Errorf(KindValidation, "%s manager %s needs a role for kubernetes auth", v.platform(), v.Profile)
+		}
+		jwtPath := v.JWTPath
+		if jwtPath == "" {
+			jwtPath = defaultKubernetesJWTPath
+		}
+		var jwt []byte
+		if jwt, err = ioutil.ReadFile(jwtPath); err != nil {
+			return nil, Errorf(KindAuth, "%s manager %s: reading the kubernetes service account token: %w", v.platform(), v.Profile, err)
+		}
+		c.Token, err = c.Login(timeCtx, v.authMount("kubernetes"), map[string]string{"role": v.Role, "jwt": strings.TrimSpace(string(jwt))})
+	case "cert":
+		if tlsConfig.ClientCert == "" {
+			return nil, Errorf(KindValidation, "%s manager %s needs client_cert and client_key for cert auth", v.platform(), v.Profile)
+		}
+		payload := map[string]string{}
+		if v.Role != "" {
+			payload["name"] = v.Role
+		}
+		c.Token, err = c.Login(timeCtx, v.authMount("cert"), payload)
+	default:
+		return nil, Errorf(KindValidation, "%s manager %s: unknown auth `%s`, use token, approle, kubernetes or cert", v.platform(), v.Profile, v.Auth)
+	}
+	if err != nil {
+		return nil, err
+	}
+	helpers.AddRedaction(c.Token)
+	v.client = c
+	return c, nil
+}
+
+// tlsConfig returns the TLS settings of the manager, unset ones are read from the environment like the vault cli does
+func (v *VaultManager) tlsConfig() vault.TLSConfig {
+	t := vault.TLSConfig{CACert: v.CACert, ClientCert: v.ClientCert, ClientKey: v.ClientKey, ServerName: v.TLSServerName}
+	if t.CACert == "" {
+		t.CACert = v.getenv("CACERT")
+	}
+	if t.ClientCert == "" {
+		t.ClientCert = v.getenv("CLIENT_CERT")
+	}
+	if t.ClientKey == "" {
+		t.ClientKey = v.getenv("CLIENT_KEY")
+	}
+	if t.ServerName == "" {
+		t.ServerName = v.getenv("TLS_SERVER_NAME")
+	}
+	return t
+}
+
+// token returns the configured token, VAULT_TOKEN (BAO_TOKEN for openbao) or the token saved by vault login
+func (v *VaultManager) token() (string, error) {
+	if v.Token != "" {
+		return v.Token, nil
+	}
+	if t := v.getenv("TOKEN"); t != "" {
+		return t, nil
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if t, err := ioutil.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+			return strings.TrimSpace(string(t)), nil
+		}
+	}
+	return "", Errorf(KindAuth, "%s manager %s has no token, set token, %s or log in with the cli", v.platform(), v.Profile, v.envName("TOKEN"))
+}
+
+func (v *VaultManager) authMount(method string) string {
+	if v.AuthMount == "" {
+		return method
+	}
+	return v.AuthMount
+}
+
+// content returns the secret content from the data of a KV secret, secrets written by other tools without
+// the field are returned as json
+func (v *VaultManager) content(data map[string]interface{}) (string, error) {
+	if s, ok := data[v.field()].(string); ok {
+		return s, nil
+	}
+	src, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(src), nil
+}
+
+// VaultManager Create
+func (v *VaultManager) Create(args []string, secretsPath string, useEditor bool) error {
+	return createLocalSecret(args, secretsPath, useEditor)
+}
+
+// VaultManager Delete soft deletes the current version of the selected secrets, vault keeps them until they
+// are destroyed so the number of days is not used
+func (v *VaultManager) Delete(scheduleInDays int64) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := v.loadClient(ctx)
+	if err != nil {
+		return err
+	}
+	sID, err := v.FuzzyFind(ctx)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("delete", v.Profile, sID); err != nil {
+		return err
+	}
+
+	var deleted []string
+	defer func() { notify("delete", v.Profile, deleted) }()
+	for _, id := range sID {
+		timeCtx, cancelDelete := context.WithTimeout(ctx, helpers.PushTimeout)
+		err = client.Delete(timeCtx, v.mount(), id)
+		cancelDelete()
+		if err != nil {
+			return err
+		}
+		deleted = append(deleted, id)
+		helpers.Infof("%s %s\n", id, color.RedString("deleted"))
+		if err = TrackDeletions(v.Profile, id); err != nil {
+			color.Yellow("%s was deleted but could not be tracked for delete cancel: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// VaultManager DeleteCancel undeletes the latest version of the given secrets, without any the secrets jaws
+// deleted are listed to select from
+func (v *VaultManager) DeleteCancel(args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := v.loadClient(ctx)
+	if err != nil {
+		return err
+	}
+	secretIDs := args
+	if len(secretIDs) == 0 {
+		d, err := LoadDeletions()
+		if err != nil {
+			return err
+		}
+		tracked := d[v.Profile]
+		idxs, err := fuzzyfinder.FindMulti(tracked, func(i int) string {
+			return tracked[i]
+		}, fuzzyfinder.WithHeader("select secrets to restore"))
+		if err != nil && !errors.Is(err, fuzzyfinder.ErrAbort) {
+			return err
+		}
+		for _, idx := range idxs {
+			secretIDs = append(secretIDs, tracked[idx])
+		}
+	}
+	if err = requireChange("restore", v.Profile, secretIDs); err != nil {
+		return err
+	}
+
+	var restored []string
+	defer func() { notify("restore", v.Profile, restored) }()
+	for _, id := range secretIDs {
+		timeCtx, cancelRestore := context.WithTimeout(ctx, helpers.PushTimeout)
+		meta, err := client.Metadata(timeCtx, v.mount(), id)
+		if err == nil && meta == nil {
+			err = Errorf(KindNotFound, "no secret found called %s", id)
+		}
+		if err == nil {
+			err = client.Undelete(timeCtx, v.mount(), id, []int{meta.CurrentVersion})
+		}
+		cancelRestore()
+		if err != nil {
+			return err
+		}
+		restored = append(restored, id)
+		helpers.Infof("%s %s\n", id, color.GreenString("restored"))
+		if err = UntrackDeletions(v.Profile, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VaultManager FuzzyFind
+func (v *VaultManager) FuzzyFind(ctx context.Context) ([]string, error) {
+	allIDs, err := v.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	return findWithPicks(allIDs, v.Profile)
+}
+
+// VaultManager Get
+func (v *VaultManager) Get(secretsIDList []string) ([]Secret, error) {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	client, err := v.loadClient(ctx)
+	if err != nil {
+		return []Secret{}, err
+	}
+	secretIDs := secretsIDList
+	if len(secretIDs) == 0 {
+		if secretIDs, err = v.FuzzyFind(ctx); err != nil {
+			return []Secret{}, err
+		}
+	}
+
+	var Secrets []Secret
+	defer helpers.StartWork()()
+	for i, id := range secretIDs {
+		if err = interrupted("pull", secretIDs[:i], secretIDs[i:]); err != nil {
+			return Secrets, err
+		}
+		timeCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+		data, err := client.Read(timeCtx, v.mount(), id, 0)
+		cancelGet()
+		if err != nil {
+			if stopErr := interrupted("pull", secretIDs[:i], secretIDs[i:]); stopErr != nil {
+				return Secrets, stopErr
+			}
+			return []Secret{}, err
+		}
+		if data == nil {
+			continue
+		}
+		content, err := v.content(data)
+		if err != nil {
+			return []Secret{}, err
+		}
+		helpers.AddRedaction(content)
+		Secrets = append(Secrets, Secret{
+			ID:        id,
+			Content:   content,
+			recipient: v.AgeRecipient,
+			profile:   v.Profile,
+		})
+		helpers.Progress(helpers.ProgressEvent{Event: helpers.EventSecretPulled, Profile: v.Profile, Secret: id})
+	}
+	return Secrets, nil
+}
+
+// VaultManager ListAll lists every secret of the KV engine
+func (v *VaultManager) ListAll() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helpers.ListTimeout)
+	defer cancel()
+
+	client, err := v.loadClient(ctx)
+	if err != nil {
+		return []string{}, err
+	}
+	return client.List(ctx, v.mount(), "")
+}
+
+// VaultManager Rollback
+func (v *VaultManager) Rollback() error {
+	return v.RollbackSteps(1)
+}
+
+// VaultManager RollbackSteps writes the version from the given number of readable versions back as a new version
+func (v *VaultManager) RollbackSteps(steps int) error {
+	if steps < 1 {
+		return Errorf(KindValidation, "steps must be at least 1")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := v.loadClient(ctx)
+	if err != nil {
+		return err
+	}
+	sID, err := v.FuzzyFind(ctx)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("rollback", v.Profile, sID); err != nil {
+		return err
+	}
+
+	var rolledBack []string
+	defer func() { notify("rollback", v.Profile, rolledBack) }()
+	for _, id := range sID {
+		if err = v.rollbackSecret(ctx, client, id, steps); err != nil {
+			return err
+		}
+		rolledBack = append(rolledBack, id)
+	}
+	return nil
+}
+
+func (v *VaultManager) rollbackSecret(ctx context.Context, client *vault.Client, id string, steps int) error {
+	timeCtx, cancel := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancel()
+	meta, err := client.Metadata(timeCtx, v.mount(), id)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		return Errorf(KindNotFound, "no secret found called %s", id)
+	}
+	live := meta.LiveVersions()
+	if len(live) <= steps {
+		older := len(live) - 1
+		if older < 0 {
+			older = 0
+		}
+		return Errorf(KindValidation, "%s has %d readable older version(s), can not roll back %d", id, older, steps)
+	}
+	return v.restore(timeCtx, client, id, meta.CurrentVersion, live[steps])
+}
+
+// restore writes the target version back as a new version, current is the version the write expects to replace
+func (v *VaultManager) restore(ctx context.Context, client *vault.Client, id string, current int, target int) error {
+	data, err := client.Read(ctx, v.mount(), id, target)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return Errorf(KindNotFound, "version %d of %s can not be read", target, id)
+	}
+	if err = client.Write(ctx, v.mount(), id, data, &current); err != nil {
+		return err
+	}
+	helpers.Infof("%s %s to version %d\n", id, color.YellowString("rolled back"), target)
+	return nil
+}
+
+// VaultManager Set pushes the local secrets as new versions, each write checks the version it replaces so
+// changes made in between are not overwritten
+func (v *VaultManager) Set(secretsPath string, createPrompt bool) error {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	client, err := v.loadClient(ctx)
+	if err != nil {
+		return err
+	}
+	sID, err := helpers.GetSecretNames(secretsPath)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("push", v.Profile, sID); err != nil {
+		return err
+	}
+
+	var pushed []string
+	defer func() {
+		trackRecent(v.Profile, pushed...)
+		notify("push", v.Profile, pushed)
+	}()
+	defer helpers.StartWork()()
+	for i, id := range sID {
+		if err = interrupted("push", sID[:i], sID[i:]); err != nil {
+			return err
+		}
+		secretUpdate, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", secretsPath, id))
+		if err != nil {
+			return err
+		}
+		value := string(secretUpdate)
+		helpers.AddRedaction(value)
+		changed, err := v.push(ctx, client, id, value, createPrompt)
+		progressPush(v.Profile, id, err, changed)
+		if err != nil {
+			if stopErr := interrupted("push", sID[:i], sID[i:]); stopErr != nil {
+				return stopErr
+			}
+			return err
+		}
+		if changed {
+			pushed = append(pushed, id)
+		}
+	}
+	return nil
+}
+
+// push writes the value as a new version of the secret, it reports whether a version was written
+func (v *VaultManager) push(ctx context.Context, client *vault.Client, id string, value string, createPrompt bool) (bool, error) {
+	getCtx, cancelGet := context.WithTimeout(ctx, helpers.GetTimeout)
+	meta, err := client.Metadata(getCtx, v.mount(), id)
+	var existing map[string]interface{}
+	if err == nil && meta != nil {
+		existing, err = client.Read(getCtx, v.mount(), id, 0)
+	}
+	cancelGet()
+	if err != nil {
+		return false, err
+	}
+
+	cas := 0
+	if meta != nil {
+		cas = meta.CurrentVersion
+		if existing != nil {
+			if current, err := v.content(existing); err == nil && current == value {
+				helpers.Infof("%s %s\n", id, color.CyanString("skipped"))
+				return false, nil
+			}
+		}
+	} else if !createPrompt && !helpers.PromptYesNo(fmt.Sprintf("%s was not found, would you like to create this secret?", id)) {
+		helpers.Infof("creation of %s %s\n", id, color.CyanString("skipped"))
+		return false, nil
+	}
+
+	pushCtx, cancelPush := context.WithTimeout(ctx, helpers.PushTimeout)
+	defer cancelPush()
+	if err = client.Write(pushCtx, v.mount(), id, v.data(existing, value), &cas); err != nil {
+		return false, err
+	}
+	if meta == nil {
+		helpers.Infof("%s %s\n", id, color.MagentaString("created"))
+	} else {
+		helpers.Infof("%s %s\n", id, color.YellowString("updated"))
+	}
+	return true, nil
+}
+
+// data returns the KV data to write for the secret content, secrets without the field that were pulled as
+// json are written back with their fields
+func (v *VaultManager) data(existing map[string]interface{}, value string) map[string]interface{} {
+	if existing != nil {
+		if _, ok := existing[v.field()]; !ok {
+			fields := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(value), &fields); err == nil {
+				return fields
+			}
+		}
+	}
+	return map[string]interface{}{v.field(): value}
+}
@@ -0,0 +1,413 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/jacbart/jaws/internal/aws"
+	"github.com/jacbart/jaws/internal/vault"
+	"github.com/jacbart/jaws/utils/helpers"
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+// SecretVersion is one version of a secret as listed by jaws history
+type SecretVersion struct {
+	Version string
+	// Stages are the staging labels of the version, or its state on providers without labels
+	Stages  []string
+	Created time.Time
+	// ChangedBy is who pushed the version, empty when the provider does not record it
+	ChangedBy string
+}
+
+// Current reports whether the version is the one pulls read
+func (v SecretVersion) Current() bool {
+	return helpers.Contains(v.Stages, "AWSCURRENT") || helpers.Contains(v.Stages, "current")
+}
+
+// FindVersion returns the version matching want, either its full ID, one of its stages or an unambiguous
+// prefix of its ID
+func FindVersion(versions []SecretVersion, want string) (SecretVersion, error) {
+	var prefixed []SecretVersion
+	for _, v := range versions {
+		if v.Version == want {
+			return v, nil
+		}
+		for _, stage := range v.Stages {
+			if strings.EqualFold(stage, want) {
+				return v, nil
+			}
+		}
+		if strings.HasPrefix(v.Version, want) {
+			prefixed = append(prefixed, v)
+		}
+	}
+	switch len(prefixed) {
+	case 0:
+		return SecretVersion{}, Errorf(KindNotFound, "no version `%s` found", want)
+	case 1:
+		return prefixed[0], nil
+	}
+	return SecretVersion{}, Errorf(KindValidation, "version `%s` matches %d versions, give more of the ID", want, len(prefixed))
+}
+
+// PickVersion opens the fuzzy finder on the versions of the secret, ok is false when nothing was picked
+func PickVersion(secretID string, versions []SecretVersion) (v SecretVersion, ok bool, err error) {
+	idx, err := fuzzyfinder.Find(versions, func(i int) string {
+		v := versions[i]
+		line := v.Version
+		if !v.Created.IsZero() {
+			line += "  " + v.Created.Local().Format("2006-01-02 15:04")
+		}
+		if len(v.Stages) != 0 {
+			line += "  " + strings.Join(v.Stages, ",")
+		}
+		if v.ChangedBy != "" {
+			line += "  " + v.ChangedBy
+		}
+		return line
+	}, fuzzyfinder.WithHeader(fmt.Sprintf("select the version of %s to roll back to", secretID)))
+	if errors.Is(err, fuzzyfinder.ErrAbort) {
+		return v, false, nil
+	}
+	if err != nil {
+		return v, false, err
+	}
+	return versions[idx], true, nil
+}
+
+// AWSManager DestroyVersions removes the staging labels of the secret versions created before the cutoff, the
+// unlabeled versions are deprecated and stay readable by version ID until AWS cleans them up on its own
+func (a *AWSManager) DestroyVersions(secretIDs []string, before time.Time, dryRun bool) (int, error) {
+	if !dryRun {
+		if err := requireChange("destroy", a.Profile, secretIDs); err != nil {
+			return 0, err
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := LoadAWSClient(a, ctx)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	var purged []string
+	defer func() { notify("destroy", a.Profile, purged) }()
+	for _, id := range secretIDs {
+		deprecated, err := aws.DestroyStages(ctx, client, id, before, dryRun)
+		total += deprecated
+		if deprecated > 0 && !dryRun {
+			purged = append(purged, id)
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// ChainManager DestroyVersions
+func (c *ChainManager) DestroyVersions(secretIDs []string, before time.Time, dryRun bool) (int, error) {
+	d, ok := c.managers[0].(VersionDestroyer)
+	if !ok {
+		return 0, Errorf(KindUnsupported, "profile %s does not keep secret versions", c.managers[0].ProfileName())
+	}
+	if !dryRun {
+		if err := requireProtected("destroy", c.Profile); err != nil {
+			return 0, err
+		}
+	}
+	return d.DestroyVersions(secretIDs, before, dryRun)
+}
+
+// AWSManager History lists every version of the secret newest first, only the current version knows who
+// pushed it through the last-changed-by tag
+func (a *AWSManager) History(secretID string) ([]SecretVersion, error) {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	client, err := LoadAWSClient(a, ctx)
+	if err != nil {
+		return nil, err
+	}
+	listed, err := aws.ListVersions(ctx, client, secretID)
+	if err != nil {
+		var rnfErr *types.ResourceNotFoundException
+		if errors.As(err, &rnfErr) {
+			return nil, Errorf(KindNotFound, "no secret found called %s", secretID)
+		}
+		return nil, err
+	}
+	changedBy, err := aws.ChangedBy(ctx, client, secretID)
+	if err != nil {
+		helpers.Verbosef(helpers.VerbosityVerbose, "reading %s of %s: %v\n", aws.ChangedByTag, secretID, err)
+	}
+	versions := make([]SecretVersion, 0, len(listed))
+	for _, l := range listed {
+		v := SecretVersion{Version: *l.VersionId, Stages: l.VersionStages}
+		if l.CreatedDate != nil {
+			v.Created = *l.CreatedDate
+		}
+		if helpers.Contains(l.VersionStages, "AWSCURRENT") {
+			v.ChangedBy = changedBy
+		}
+		versions = append(versions, v)
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Created.After(versions[j].Created)
+	})
+	return versions, nil
+}
+
+// AWSManager GetVersion
+func (a *AWSManager) GetVersion(secretID string, version string) (Secret, error) {
+	ctx, cancel := context.WithCancel(helpers.Context())
+	defer cancel()
+
+	client, err := LoadAWSClient(a, ctx)
+	if err != nil {
+		return Secret{}, err
+	}
+	vout, err := aws.GetVersion(ctx, client, secretID, version)
+	if err != nil {
+		return Secret{}, err
+	}
+	value := secretValue(vout)
+	if value == nil {
+		return Secret{}, Errorf(KindNotFound, "version %s of %s has no value", version, secretID)
+	}
+	value.ID = secretID
+	value.recipient = a.AgeRecipient
+	value.profile = a.Profile
+	return *value, nil
+}
+
+// AWSManager RestoreVersion pushes the value of the version as the new current version
+func (a *AWSManager) RestoreVersion(secretID string, version string) error {
+	defer a.cache.reset()
+	if err := requireChange("rollback", a.Profile, []string{secretID}); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := LoadAWSClient(a, ctx)
+	if err != nil {
+		return err
+	}
+	if err = aws.RestoreVersion(ctx, client, secretID, version); err != nil {
+		return err
+	}
+	a.tagChangedBy(ctx, client, secretID)
+	notify("rollback", a.Profile, []string{secretID})
+	return nil
+}
+
+// VaultManager History lists every version of the KV secret newest first, deleted and destroyed versions
+// are listed with their state as the stage
+func (v *VaultManager) History(secretID string) ([]SecretVersion, error) {
+	ctx, cancel := context.WithTimeout(helpers.Context(), helpers.GetTimeout)
+	defer cancel()
+
+	client, err := v.loadClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := client.Metadata(ctx, v.mount(), secretID)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, Errorf(KindNotFound, "no secret found called %s", secretID)
+	}
+	versions := make([]SecretVersion, 0, len(meta.Versions))
+	for n, m := range meta.Versions {
+		versions = append(versions, SecretVersion{Version: n, Stages: vaultStages(meta, n, m), Created: m.CreatedTime})
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, _ := strconv.Atoi(versions[i].Version)
+		b, _ := strconv.Atoi(versions[j].Version)
+		return a > b
+	})
+	return versions, nil
+}
+
+func vaultStages(meta *vault.Metadata, n string, m vault.Version) []string {
+	switch {
+	case m.Destroyed:
+		return []string{"destroyed"}
+	case m.DeletionTime != "":
+		return []string{"deleted"}
+	case n == strconv.Itoa(meta.CurrentVersion):
+		return []string{"current"}
+	}
+	return nil
+}
+
+// VaultManager GetVersion
+func (v *VaultManager) GetVersion(secretID string, version string) (Secret, error) {
+	n, err := strconv.Atoi(version)
+	if err != nil || n < 1 {
+		return Secret{}, Errorf(KindValidation, "`%s` is not a vault version number", version)
+	}
+	ctx, cancel := context.WithTimeout(helpers.Context(), helpers.GetTimeout)
+	defer cancel()
+
+	client, err := v.loadClient(ctx)
+	if err != nil {
+		return Secret{}, err
+	}
+	data, err := client.Read(ctx, v.mount(), secretID, n)
+	if err != nil {
+		return Secret{}, err
+	}
+	if data == nil {
+		return Secret{}, Errorf(KindNotFound, "version %s of %s can not be read", version, secretID)
+	}
+	content, err := v.content(data)
+	if err != nil {
+		return Secret{}, err
+	}
+	return Secret{ID: secretID, Content: content, recipient: v.AgeRecipient, profile: v.Profile}, nil
+}
+
+// VaultManager RestoreVersion writes the version back as a new version
+func (v *VaultManager) RestoreVersion(secretID string, version string) error {
+	n, err := strconv.Atoi(version)
+	if err != nil || n < 1 {
+		return Errorf(KindValidation, "`%s` is not a vault version number", version)
+	}
+	if err = requireChange("rollback", v.Profile, []string{secretID}); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), helpers.PushTimeout)
+	defer cancel()
+
+	client, err := v.loadClient(ctx)
+	if err != nil {
+		return err
+	}
+	meta, err := client.Metadata(ctx, v.mount(), secretID)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		return Errorf(KindNotFound, "no secret found called %s", secretID)
+	}
+	if err = v.restore(ctx, client, secretID, meta.CurrentVersion, n); err != nil {
+		return err
+	}
+	notify("rollback", v.Profile, []string{secretID})
+	return nil
+}
+
+// AzureManager History lists the enabled versions of the secret newest first
+func (z *AzureManager) History(secretID string) ([]SecretVersion, error) {
+	name, err := z.name(secretID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := z.loadClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(helpers.Context(), helpers.GetTimeout)
+	defer cancel()
+	listed, err := client.Versions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(listed) == 0 {
+		return nil, Errorf(KindNotFound, "no secret found called %s", secretID)
+	}
+	versions := make([]SecretVersion, 0, len(listed))
+	for i, l := range listed {
+		v := SecretVersion{Version: l.Version(), Created: time.Unix(l.Attributes.Created, 0)}
+		if i == 0 {
+			v.Stages = []string{"current"}
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// AzureManager GetVersion
+func (z *AzureManager) GetVersion(secretID string, version string) (Secret, error) {
+	name, err := z.name(secretID)
+	if err != nil {
+		return Secret{}, err
+	}
+	client, err := z.loadClient()
+	if err != nil {
+		return Secret{}, err
+	}
+	ctx, cancel := context.WithTimeout(helpers.Context(), helpers.GetTimeout)
+	defer cancel()
+	value, err := client.GetSecret(ctx, name, version)
+	if err != nil {
+		return Secret{}, err
+	}
+	if value == nil {
+		return Secret{}, Errorf(KindNotFound, "version %s of %s was not found", version, secretID)
+	}
+	return Secret{ID: secretID, Content: *value, recipient: z.AgeRecipient, profile: z.Profile}, nil
+}
+
+// AzureManager RestoreVersion sets the value of the version as a new version
+func (z *AzureManager) RestoreVersion(secretID string, version string) error {
+	name, err := z.name(secretID)
+	if err != nil {
+		return err
+	}
+	if err = requireChange("rollback", z.Profile, []string{secretID}); err != nil {
+		return err
+	}
+	client, err := z.loadClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), helpers.PushTimeout)
+	defer cancel()
+	if err = z.restore(ctx, client, secretID, name, version); err != nil {
+		return err
+	}
+	notify("rollback", z.Profile, []string{secretID})
+	return nil
+}
+
+// ChainManager History
+func (c *ChainManager) History(secretID string) ([]SecretVersion, error) {
+	h, ok := c.managers[0].(Historian)
+	if !ok {
+		return nil, Errorf(KindUnsupported, "profile %s does not keep secret versions", c.managers[0].ProfileName())
+	}
+	return h.History(secretID)
+}
+
+// ChainManager GetVersion
+func (c *ChainManager) GetVersion(secretID string, version string) (Secret, error) {
+	h, ok := c.managers[0].(Historian)
+	if !ok {
+		return Secret{}, Errorf(KindUnsupported, "profile %s does not keep secret versions", c.managers[0].ProfileName())
+	}
+	return h.GetVersion(secretID, version)
+}
+
+// ChainManager RestoreVersion
+func (c *ChainManager) RestoreVersion(secretID string, version string) error {
+	h, ok := c.managers[0].(Historian)
+	if !ok {
+		return Errorf(KindUnsupported, "profile %s does not keep secret versions", c.managers[0].ProfileName())
+	}
+	if err := requireProtected("rollback", c.Profile); err != nil {
+		return err
+	}
+	return h.RestoreVersion(secretID, version)
+}
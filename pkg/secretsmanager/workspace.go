@@ -0,0 +1,69 @@
+package secretsmanager
+
+import (
+	"github.com/fatih/color"
+	"github.com/jacbart/jaws/utils/helpers"
+)
+
+// WorkspaceHCL bundles the defaults of a context jaws can switch to with jaws workspace use
+type WorkspaceHCL struct {
+	Name        string   `hcl:"name,label"`
+	Profile     string   `hcl:"profile,optional"`
+	SecretsPath string   `hcl:"secrets_path,optional"`
+	Prefixes    []string `hcl:"prefixes,optional"`
+}
+
+// workspaceState is the state file content naming the workspace in use
+type workspaceState struct {
+	Active string `json:"active"`
+}
+
+// workspaceFile returns the path of the state file naming the workspace in use
+func workspaceFile() string {
+	return helpers.StatePath("workspace.json")
+}
+
+// findWorkspace returns the workspace with the name
+func findWorkspace(workspaces []WorkspaceHCL, name string) (*WorkspaceHCL, error) {
+	var names []string
+	for i := range workspaces {
+		if workspaces[i].Name == name {
+			return &workspaces[i], nil
+		}
+		names = append(names, workspaces[i].Name)
+	}
+	helpers.PrintSuggestions(name, helpers.Suggest(name, names))
+	return nil, Errorf(KindNotFound, "no workspace configured with the name `%s`", name)
+}
+
+// ActiveWorkspace returns the workspace in use, nil when none is. A workspace that was removed from the config
+// only prints a warning so jaws keeps working with the defaults.
+func ActiveWorkspace(workspaces []WorkspaceHCL) (*WorkspaceHCL, error) {
+	var state workspaceState
+	if err := loadState(workspaceFile(), &state); err != nil {
+		return nil, err
+	}
+	if state.Active == "" {
+		return nil, nil
+	}
+	for i := range workspaces {
+		if workspaces[i].Name == state.Active {
+			return &workspaces[i], nil
+		}
+	}
+	color.Yellow("workspace %s is not in the config anymore, run jaws workspace clear to stop using it", state.Active)
+	return nil, nil
+}
+
+// UseWorkspace switches to the workspace for the next runs of jaws
+func UseWorkspace(workspaces []WorkspaceHCL, name string) error {
+	if _, err := findWorkspace(workspaces, name); err != nil {
+		return err
+	}
+	return saveState(workspaceFile(), workspaceState{Active: name})
+}
+
+// ClearWorkspace stops using a workspace
+func ClearWorkspace() error {
+	return saveState(workspaceFile(), workspaceState{})
+}
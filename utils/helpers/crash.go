@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// AppVersion is written to crash reports, the cli sets it to the version it was built with
+var AppVersion = "dev"
+
+// HandlePanic recovers a panic, restores the terminal, writes a crash report and exits with 1. It has to be
+// deferred directly, i.e. defer helpers.HandlePanic(), by main and by any goroutine that can panic.
+func HandlePanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	RestoreTerminal()
+	fmt.Fprintln(os.Stderr, color.RedString("jaws crashed unexpectedly, sorry about that"))
+	path, err := writeCrashReport(r, debug.Stack())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "the crash report could not be written: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "a crash report was written to %s\nplease attach it to an issue at https://github.com/jacbart/jaws/issues\n", path)
+	}
+	os.Exit(1)
+}
+
+// writeCrashReport writes the panic and stack trace to the state directory. Known secret values are redacted
+// and the home directory is replaced with ~ so the report can be shared.
+func writeCrashReport(r interface{}, stack []byte) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "time: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "version: %s\n", AppVersion)
+	fmt.Fprintf(&b, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "command: jaws %s\n", ShellFormat(os.Args[1:]))
+	fmt.Fprintf(&b, "panic: %v\n\n%s", r, stack)
+	report := Redact(b.String())
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		report = strings.ReplaceAll(report, home, "~")
+	}
+
+	path := StatePath(fmt.Sprintf("crash-%s.txt", time.Now().Format("20060102-150405")))
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(report), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appName is the directory name jaws uses inside the config, cache and state directories
+const appName = "jaws"
+
+// ConfigDir returns the directory holding jaws.conf, $XDG_CONFIG_HOME/jaws or the platform equivalent
+func ConfigDir() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, appName)
+	}
+	return LegacyDir()
+}
+
+// CacheDir returns the directory for data jaws can rebuild, $XDG_CACHE_HOME/jaws or the platform equivalent
+func CacheDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, appName)
+	}
+	return filepath.Join(LegacyDir(), "cache")
+}
+
+// StateDir returns the directory for state jaws keeps between runs, $XDG_STATE_HOME/jaws,
+// ~/.local/state/jaws or %LocalAppData%\jaws\state on windows
+func StateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, appName)
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return filepath.Join(dir, appName, "state")
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", appName)
+	}
+	return filepath.Join(LegacyDir(), "state")
+}
+
+// LegacyDir returns ~/.jaws which older versions of jaws used for everything
+func LegacyDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+	return filepath.Join(home, ".jaws")
+}
+
+// StatePath returns the path of a file in the state directory, a file left in ~/.jaws by an older version
+// of jaws is moved there first
+func StatePath(name string) string {
+	path := filepath.Join(StateDir(), name)
+	legacy := filepath.Join(LegacyDir(), name)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if _, err := os.Stat(legacy); err == nil {
+			if err = os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+				if err = os.Rename(legacy, path); err == nil {
+					Verbosef(VerbosityVerbose, "moved %s to %s\n", legacy, path)
+				}
+			}
+		}
+	}
+	return path
+}
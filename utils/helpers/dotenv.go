@@ -0,0 +1,86 @@
+package helpers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseDotEnv reads KEY=value pairs from a dotenv formatted reader, it skips comments and blank lines,
+// strips an optional leading export and unquotes single or double quoted values
+func ParseDotEnv(r io.Reader) (map[string]string, error) {
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		key, value, ok, err := parseDotEnvLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if ok {
+			vars[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// DotEnvKey returns the key of a dotenv line, ok is false for comments, blank or malformed lines
+func DotEnvKey(line string) (string, bool) {
+	key, _, ok, err := parseDotEnvLine(line)
+	if err != nil {
+		return "", false
+	}
+	return key, ok
+}
+
+// DotEnvValue returns the key and unquoted value of a dotenv line, ok is false for comments, blank or malformed lines
+func DotEnvValue(line string) (string, string, bool) {
+	key, value, ok, err := parseDotEnvLine(line)
+	if err != nil {
+		return "", "", false
+	}
+	return key, value, ok
+}
+
+// FormatDotEnvLine returns a KEY="value" line, quoting the value when needed
+func FormatDotEnvLine(key string, value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n\"'#=$\\") {
+		return fmt.Sprintf("%s=%s", key, strconv.Quote(value))
+	}
+	return fmt.Sprintf("%s=%s", key, value)
+}
+
+func parseDotEnvLine(line string) (string, string, bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false, nil
+	}
+	line = strings.TrimPrefix(line, "export ")
+	kv := strings.SplitN(line, "=", 2)
+	if len(kv) != 2 {
+		return "", "", false, fmt.Errorf("missing '=' in %q", line)
+	}
+	key := strings.TrimSpace(kv[0])
+	value := strings.TrimSpace(kv[1])
+	switch {
+	case strings.HasPrefix(value, `"`):
+		v, err := strconv.Unquote(value)
+		if err != nil {
+			return "", "", false, fmt.Errorf("unquoting value of %s: %w", key, err)
+		}
+		value = v
+	case strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") && len(value) > 1:
+		value = value[1 : len(value)-1]
+	default:
+		if i := strings.Index(value, " #"); i != -1 {
+			value = strings.TrimSpace(value[:i])
+		}
+	}
+	return key, value, true, nil
+}
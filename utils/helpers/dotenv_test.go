@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"plain", "A=1\nB=two", map[string]string{"A": "1", "B": "two"}, false},
+		{"comments and blank lines", "# comment\n\nA=1\n  # indented\n", map[string]string{"A": "1"}, false},
+		{"export", "export A=1", map[string]string{"A": "1"}, false},
+		{"double quoted", `A="a b\nc"`, map[string]string{"A": "a b\nc"}, false},
+		{"single quoted", `A='a "b" $c'`, map[string]string{"A": `a "b" $c`}, false},
+		{"inline comment", "A=1 # note", map[string]string{"A": "1"}, false},
+		{"hash without space", "A=a#b", map[string]string{"A": "a#b"}, false},
+		{"equals in value", "A=b=c", map[string]string{"A": "b=c"}, false},
+		{"empty value", "A=", map[string]string{"A": ""}, false},
+		{"missing equals", "A", nil, true},
+		{"bad double quote", `A="a`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDotEnv(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDotEnv(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDotEnv(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDotEnvLine(t *testing.T) {
+	for _, value := range []string{"plain", "", "a b", "line\nbreak", `quote " and ' `, "a#b", "$HOME", `back\slash`} {
+		line := FormatDotEnvLine("KEY", value)
+		key, got, ok := DotEnvValue(line)
+		if !ok || key != "KEY" || got != value {
+			t.Errorf("FormatDotEnvLine(%q) = %s, read back as %q %q %v", value, line, key, got, ok)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Charsets are the character sets a generated value can be drawn from, joined with + like alnum+symbols
+var Charsets = map[string]string{
+	"lower":   "abcdefghijklmnopqrstuvwxyz",
+	"upper":   "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"digits":  "0123456789",
+	"alpha":   "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"alnum":   "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
+	"hex":     "0123456789abcdef",
+	"symbols": "!#%&()*+,-./:;<=>?@[]^_{|}~",
+}
+
+// CharsetNames returns the sorted names of the character sets
+func CharsetNames() []string {
+	names := make([]string, 0, len(Charsets))
+	for name := range Charsets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RandomString returns a value of the given length drawn from the charset with crypto/rand, charset joins the
+// names of Charsets with +, characters in more than one of them are not drawn more often
+func RandomString(length int, charset string) (string, error) {
+	if length < 1 {
+		return "", fmt.Errorf("length must be at least 1")
+	}
+	seen := map[rune]bool{}
+	var chars []rune
+	for _, name := range strings.Split(charset, "+") {
+		set, ok := Charsets[strings.TrimSpace(name)]
+		if !ok {
+			return "", fmt.Errorf("unknown charset `%s`, available charsets are %s", name, strings.Join(CharsetNames(), ","))
+		}
+		for _, c := range set {
+			if !seen[c] {
+				seen[c] = true
+				chars = append(chars, c)
+			}
+		}
+	}
+	max := big.NewInt(int64(len(chars)))
+	value := make([]rune, length)
+	for i := range value {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		value[i] = chars[n.Int64()]
+	}
+	return string(value), nil
+}
@@ -1,8 +1,10 @@
 package helpers
 
 import (
+	"errors"
 	"os"
 	"os/exec"
+	"path/filepath"
 )
 
 func GitDiff(secretsPath string) error {
@@ -26,3 +28,32 @@ func GitStatus(secretsPath string) error {
 func NewGitDiff(secretsPath string) error {
 	return nil
 }
+
+// GitDiffValues prints the differences between two values with git diff, the names label the values
+func GitDiffValues(oldName string, oldValue []byte, newName string, newValue []byte) error {
+	dir, err := os.MkdirTemp("", "jaws-diff-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if oldName == newName {
+		newName += "~"
+	}
+	if err = os.WriteFile(filepath.Join(dir, oldName), oldValue, 0600); err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(dir, newName), newValue, 0600); err != nil {
+		return err
+	}
+	c := exec.Command("git", "diff", "--no-index", "--", oldName, newName)
+	c.Dir = dir
+	c.Stderr = os.Stderr
+	c.Stdout = os.Stdout
+	err = c.Run()
+	// git diff --no-index exits with 1 when the files differ
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return nil
+	}
+	return err
+}
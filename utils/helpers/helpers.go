@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
@@ -38,6 +40,35 @@ func OpenEditor(secretsIDs []string, secretsPath string) error {
 	return nil
 }
 
+// PromptYesNo prints the question, reads the answer from stdin and returns true if the answer is y or yes
+func PromptYesNo(question string) bool {
+	var userResponse string
+	fmt.Fprintf(InfoOutput, "%s [y/N] ", question)
+	fmt.Scanln(&userResponse)
+
+	userResponse = strings.TrimSpace(userResponse)
+	userResponse = strings.ToLower(userResponse)
+	return userResponse == "y" || userResponse == "yes"
+}
+
+// PromptText prints the question and returns the trimmed answer
+func PromptText(question string) string {
+	var userResponse string
+	fmt.Fprintf(InfoOutput, "%s ", question)
+	fmt.Scanln(&userResponse)
+	return strings.TrimSpace(userResponse)
+}
+
+// Contains reports whether the list includes s
+func Contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
 func CheckIfGitRepo(path string, shouldWarn bool) bool {
 	_, err := os.Stat(fmt.Sprintf("%s/.git", path))
 	if os.IsNotExist(err) {
@@ -103,3 +134,47 @@ func GitControlSecrets(secretIDs []string, secretsPath string) error {
 	}
 	return nil
 }
+
+// GitCommitAll commits every change in the secrets path with the message, nothing is done when the path is not a git repo
+func GitCommitAll(secretsPath string, message string) error {
+	if !CheckIfGitRepo(secretsPath, false) {
+		return nil
+	}
+	repo, err := git.PlainOpen(secretsPath)
+	if err != nil {
+		return err
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	if err = w.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return err
+	}
+	_, err = w.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Fidelius Charm",
+			Email: "firm@local.com",
+			When:  time.Now(),
+		},
+	})
+	return err
+}
+
+// IsInteractive reports whether stdin is a terminal
+func IsInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// GlobMatch matches s against a shell glob, a pattern ending in ** matches everything under that prefix
+func GlobMatch(pattern string, s string) bool {
+	if strings.HasSuffix(pattern, "**") {
+		return strings.HasPrefix(s, strings.TrimSuffix(pattern, "**"))
+	}
+	matched, err := path.Match(pattern, s)
+	return err == nil && matched
+}
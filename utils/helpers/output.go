@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+)
+
+// Verbosity levels, set from the global --quiet and --verbose flags
+const (
+	VerbosityQuiet   = -1
+	VerbosityDefault = 0
+	VerbosityVerbose = 1
+	VerbosityDebug   = 2
+)
+
+// Verbosity is the current verbosity level of jaws
+var Verbosity = VerbosityDefault
+
+// InfoOutput receives informational lines, warnings and prompts, ReserveStdout moves it to stderr
+var InfoOutput io.Writer = os.Stdout
+
+// ReserveStdout sends informational lines, warnings and prompts to stderr so stdout only carries what a
+// command prints on purpose, i.e. secret values of jaws get --print
+func ReserveStdout() {
+	InfoOutput = os.Stderr
+	color.Output = color.Error
+}
+
+// Infof prints informational messages such as created, updated or skipped lines unless quiet is set
+func Infof(format string, a ...interface{}) {
+	if Verbosity > VerbosityQuiet {
+		fmt.Fprintf(InfoOutput, format, a...)
+	}
+}
+
+// Verbosef prints to stderr when the verbosity is at least the given level, secret values registered with
+// AddRedaction are replaced so the output is safe to share
+func Verbosef(level int, format string, a ...interface{}) {
+	if Verbosity >= level {
+		fmt.Fprint(os.Stderr, Redact(fmt.Sprintf(format, a...)))
+	}
+}
@@ -4,8 +4,39 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// AgeExtension is added to the file name of secrets pulled encrypted with age, files with it are never pushed
+const AgeExtension = ".age"
+
+// GetSecretNames lists the secrets under secretsPath, hidden files and secrets pulled encrypted are skipped so
+// their ciphertext is never pushed as a new secret
+func GetSecretNames(secretsPath string) ([]string, error) {
+	var secretNames []string
+	err := filepath.WalkDir(secretsPath,
+		func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			info, err := os.Lstat(path)
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				secretID := strings.TrimPrefix(path, fmt.Sprintf("%s/", secretsPath))
+				if !strings.HasPrefix(secretID, ".") && !strings.HasSuffix(secretID, AgeExtension) {
+					secretNames = append(secretNames, secretID)
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return []string{}, err
+	}
+	return secretNames, nil
+}
+
 // Path prints directory path to secrets folder and will create the path if it does not exist
 func Path(secretsPath string) error {
 	var f string
@@ -37,8 +68,15 @@ func Path(secretsPath string) error {
 	return nil
 }
 
-func PathCommand() {
-	shCommand := `function jaws-cd() {
+// PathCommand prints the jaws-cd shell function for the given shell, if shell is empty it is detected from $SHELL
+func PathCommand(shell string) error {
+	if shell == "" {
+		shell = DetectShell()
+	}
+	var shCommand string
+	switch shell {
+	case "bash", "zsh", "sh":
+		shCommand = `function jaws-cd() {
   if [[ $(pwd) == $(jaws path) ]]; then
     popd;
   else
@@ -47,5 +85,40 @@ func PathCommand() {
 }
 
 alias jd=jaws-cd`
+	case "fish":
+		shCommand = `function jaws-cd
+  if test (pwd) = (jaws path)
+    popd
+  else
+    pushd (jaws path)
+  end
+end
+
+alias jd=jaws-cd`
+	case "powershell", "pwsh":
+		shCommand = `function jaws-cd {
+  if ((Get-Location).Path -eq (jaws path)) {
+    Pop-Location
+  } else {
+    Push-Location (jaws path)
+  }
+}
+
+Set-Alias -Name jd -Value jaws-cd`
+	default:
+		return fmt.Errorf("unsupported shell `%s`, use one of bash, zsh, fish or powershell", shell)
+	}
 	fmt.Println(shCommand)
+	return nil
+}
+
+// DetectShell guesses the current shell from the environment, defaulting to bash
+func DetectShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return filepath.Base(shell)
+	}
+	if os.Getenv("PSModulePath") != "" {
+		return "powershell"
+	}
+	return "bash"
 }
@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGetSecretNames(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app/db-password", "app/api-key.age", "top", ".hidden"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := GetSecretNames(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	want := []string{"app/db-password", "top"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSecretNames = %q, want %q", got, want)
+	}
+}
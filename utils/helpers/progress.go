@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Progress event names
+const (
+	EventSecretPulled = "secret_pulled"
+	EventFileWritten  = "file_written"
+	EventPushResult   = "push_result"
+)
+
+// ProgressJSON streams progress events to stderr as one json object per line, set from --progress json
+var ProgressJSON bool
+
+var progressMu sync.Mutex
+
+// ProgressEvent is a step of a pull or push reported to wrappers of jaws, secret values are never part of it
+type ProgressEvent struct {
+	Event   string    `json:"event"`
+	Time    time.Time `json:"time"`
+	Profile string    `json:"profile,omitempty"`
+	Secret  string    `json:"secret,omitempty"`
+	Path    string    `json:"path,omitempty"`
+	Result  string    `json:"result,omitempty"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Progress writes the event to stderr when json progress is turned on
+func Progress(e ProgressEvent) {
+	if !ProgressJSON {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	fmt.Fprintln(os.Stderr, Redact(string(line)))
+}
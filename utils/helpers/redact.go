@@ -0,0 +1,60 @@
+package helpers
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redactedText replaces secret values in debug output
+const redactedText = "[REDACTED]"
+
+// minRedactLength is the shortest value that is redacted, shorter ones would hide ordinary words in the output
+const minRedactLength = 4
+
+var (
+	redactMu       sync.RWMutex
+	redactValues   = map[string]bool{}
+	redactReplacer = strings.NewReplacer()
+)
+
+// AddRedaction registers secret values that are removed from any verbose or debug output, every line of a
+// multi-line value is registered on its own as well
+func AddRedaction(values ...string) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	added := false
+	for _, v := range values {
+		for _, candidate := range append([]string{v}, strings.Split(v, "\n")...) {
+			candidate = strings.TrimSpace(candidate)
+			if len(candidate) < minRedactLength || redactValues[candidate] {
+				continue
+			}
+			redactValues[candidate] = true
+			added = true
+		}
+	}
+	if !added {
+		return
+	}
+	sorted := make([]string, 0, len(redactValues))
+	for v := range redactValues {
+		sorted = append(sorted, v)
+	}
+	// longer values first so a value containing another one is redacted whole
+	sort.Slice(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+	pairs := make([]string, 0, len(sorted)*2)
+	for _, v := range sorted {
+		pairs = append(pairs, v, redactedText)
+	}
+	redactReplacer = strings.NewReplacer(pairs...)
+}
+
+// Redact replaces every registered secret value in s
+func Redact(s string) string {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	return redactReplacer.Replace(s)
+}
@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ScheduleCommand runs the command once the delay has passed using systemd-run if available and at otherwise,
+// it returns the name of the scheduler used
+func ScheduleCommand(after time.Duration, command []string) (string, error) {
+	if _, err := exec.LookPath("systemd-run"); err == nil {
+		args := append([]string{"--user", "--collect", fmt.Sprintf("--on-active=%ds", int(math.Ceil(after.Seconds())))}, command...)
+		if out, err := exec.Command("systemd-run", args...).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("systemd-run: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return "systemd-run", nil
+	}
+	if _, err := exec.LookPath("at"); err == nil {
+		minutes := int(math.Ceil(after.Minutes()))
+		c := exec.Command("at", "now", "+", fmt.Sprint(minutes), "minutes")
+		c.Stdin = strings.NewReader(ShellJoin(command) + "\n")
+		if out, err := c.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("at: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return "at", nil
+	}
+	return "", fmt.Errorf("neither systemd-run nor at was found to schedule the command")
+}
+
+// ShellJoin single quotes each argument so the command can be safely run by a shell
+func ShellJoin(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, a := range args {
+		quoted = append(quoted, ShellQuote(a))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ShellQuote wraps s in single quotes, escaping any single quotes inside of it
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ShellFormat joins the arguments for display, only quoting the ones a shell would not read back as they are
+func ShellFormat(args []string) string {
+	formatted := make([]string, 0, len(args))
+	for _, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n'\"\\$`*?[]{}()<>|&;#~!") {
+			a = ShellQuote(a)
+		}
+		formatted = append(formatted, a)
+	}
+	return strings.Join(formatted, " ")
+}
+
+// ShellSplit splits a command line into arguments like a shell would, honoring single and double quotes and
+// backslash escapes. Variables and globs are not expanded.
+func ShellSplit(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in `%s`", quote, s)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in `%s`", s)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShellSplit(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"jaws get a b", []string{"jaws", "get", "a", "b"}, false},
+		{"  spaced\tout  ", []string{"spaced", "out"}, false},
+		{`echo 'a b' "c d"`, []string{"echo", "a b", "c d"}, false},
+		{`echo 'a \n' "b \" c"`, []string{"echo", `a \n`, `b " c`}, false},
+		{`a\ b`, []string{"a b"}, false},
+		{`''`, []string{""}, false},
+		{`x"y"'z'`, []string{"xyz"}, false},
+		{"", nil, false},
+		{`echo 'open`, nil, true},
+		{`echo trailing\`, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ShellSplit(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ShellSplit(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ShellSplit(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for _, value := range []string{"plain", "", "it's", "''", "a b $HOME `x`", "line\nbreak"} {
+		got, err := ShellSplit(ShellQuote(value))
+		if err != nil || len(got) != 1 || got[0] != value {
+			t.Errorf("ShellQuote(%q) = %s, split back as %q (%v)", value, ShellQuote(value), got, err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var (
+	signalCtx, cancelSignalCtx = context.WithCancel(context.Background())
+
+	signalMu   sync.Mutex
+	received   os.Signal
+	workActive int
+)
+
+// Context returns the context canceled by the first SIGINT or SIGTERM jaws receives
+func Context() context.Context {
+	return signalCtx
+}
+
+// Interrupted reports whether jaws received SIGINT or SIGTERM
+func Interrupted() bool {
+	return signalCtx.Err() != nil
+}
+
+// Received returns the signal jaws received, nil before any arrived
+func Received() os.Signal {
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	return received
+}
+
+// SignalExitCode returns the shell exit code of the received signal, 128 + the signal number
+func SignalExitCode() int {
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	if sig, ok := received.(syscall.Signal); ok {
+		return 128 + int(sig)
+	}
+	return 130
+}
+
+// StartWork marks the start of a pull or push that watches Context, the returned func marks its end. While
+// work is running a signal only cancels Context so the work can stop between secrets and report what it got
+// done, without any running a signal restores the terminal and exits right away.
+func StartWork() func() {
+	signalMu.Lock()
+	workActive++
+	signalMu.Unlock()
+	return func() {
+		signalMu.Lock()
+		workActive--
+		signalMu.Unlock()
+	}
+}
+
+// HandleSignals starts listening for SIGINT and SIGTERM, a second signal always exits right away
+func HandleSignals() {
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-c
+		signalMu.Lock()
+		received = sig
+		working := workActive > 0
+		signalMu.Unlock()
+		cancelSignalCtx()
+		if working {
+			Verbosef(VerbosityVerbose, "received %s, stopping after the current secret\n", sig)
+			<-c
+		}
+		RestoreTerminal()
+		os.Exit(SignalExitCode())
+	}()
+}
@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultSuggestDistance is the largest edit distance used when none is configured
+const DefaultSuggestDistance = 3
+
+// maxSuggestions caps how many suggestions are returned
+const maxSuggestions = 5
+
+// SuggestDistance is the largest edit distance between an input and a candidate that is still suggested,
+// a negative value turns suggestions off
+var SuggestDistance = DefaultSuggestDistance
+
+// Suggest returns the candidates similar to input, closest first. Candidates containing the input are also suggested.
+func Suggest(input string, candidates []string) []string {
+	if SuggestDistance < 0 || input == "" {
+		return nil
+	}
+	type match struct {
+		candidate string
+		distance  int
+	}
+	var matches []match
+	in := strings.ToLower(input)
+	for _, c := range candidates {
+		lc := strings.ToLower(c)
+		if lc == in {
+			continue
+		}
+		d := Levenshtein(in, lc)
+		if d <= SuggestDistance || (len(in) > 2 && strings.Contains(lc, in)) {
+			matches = append(matches, match{c, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+	var suggestions []string
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, matches[i].candidate)
+	}
+	return suggestions
+}
+
+// PrintSuggestions prints the suggestions for input, nothing is printed without suggestions
+func PrintSuggestions(input string, suggestions []string) {
+	if len(suggestions) == 0 {
+		return
+	}
+	fmt.Fprintf(InfoOutput, "did you mean this instead of %s?\n", input)
+	for _, s := range suggestions {
+		fmt.Fprintf(InfoOutput, "\t%s\n", s)
+	}
+}
+
+// Levenshtein returns the edit distance between a and b
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j] + 1
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+			if prev[j-1]+cost < curr[j] {
+				curr[j] = prev[j-1] + cost
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// FuzzyMatch reports whether the characters of pattern appear in s in order, ignoring case
+func FuzzyMatch(pattern string, s string) bool {
+	p := []rune(strings.ToLower(pattern))
+	i := 0
+	for _, r := range strings.ToLower(s) {
+		if i < len(p) && r == p[i] {
+			i++
+		}
+	}
+	return i == len(p)
+}
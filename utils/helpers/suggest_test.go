@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"list", "list", 0},
+		{"lsit", "list", 2},
+		{"lisst", "list", 1},
+		{"kitten", "sitting", 3},
+		{"héllo", "hello", 1},
+	}
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	defer func(d int) { SuggestDistance = d }(SuggestDistance)
+	candidates := []string{"prod/app/db-password", "prod/app/db-user", "staging/app/db-password"}
+
+	SuggestDistance = DefaultSuggestDistance
+	if got, want := Suggest("prod/app/db-pasword", candidates), []string{"prod/app/db-password"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest = %q, want %q", got, want)
+	}
+	if got, want := Suggest("db-password", candidates), []string{"prod/app/db-password", "staging/app/db-password"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest of a part = %q, want %q", got, want)
+	}
+	SuggestDistance = -1
+	if got := Suggest("prod/app/db-pasword", candidates); got != nil {
+		t.Errorf("Suggest with suggestions off = %q, want none", got)
+	}
+}
@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+var (
+	tty           *os.File
+	terminalState *term.State
+)
+
+// SaveTerminal remembers the state of the controlling terminal so RestoreTerminal can undo the raw mode a
+// fuzzy finder leaves behind when it is interrupted. Without a terminal it does nothing.
+func SaveTerminal() {
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return
+	}
+	state, err := term.GetState(int(f.Fd()))
+	if err != nil {
+		f.Close()
+		return
+	}
+	tty, terminalState = f, state
+}
+
+// RestoreTerminal puts the terminal back into the state saved by SaveTerminal
+func RestoreTerminal() {
+	if terminalState == nil {
+		return
+	}
+	// show the cursor and leave the alternate screen used by the fuzzy finder
+	fmt.Fprint(tty, "\x1b[?25h\x1b[?1049l")
+	if err := term.Restore(int(tty.Fd()), terminalState); err != nil {
+		Verbosef(VerbosityDebug, "restoring the terminal: %v\n", err)
+	}
+}
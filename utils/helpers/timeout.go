@@ -0,0 +1,10 @@
+package helpers
+
+import "time"
+
+// Timeouts applied to each call made to a secret manager, overridden from the jaws config or the --timeout flag
+var (
+	ListTimeout = 30 * time.Second
+	GetTimeout  = 10 * time.Second
+	PushTimeout = 10 * time.Second
+)